@@ -5,10 +5,14 @@ import (
 	"time"
 )
 
+// FlightStatsStore retrieves flight stats for a route.
+//
+// from and to limit the results to flights between the two dates
+// (inclusive). A zero time means the range is unbounded on that side.
 type FlightStatsStore interface {
-	FlightStatsByAirline(ctx context.Context, origin, destination string) ([]*FlightStats, error)
-	DailyFlightStats(ctx context.Context, origin, destination string) (map[string][]*FlightStatsByDateRow, error)
-	MonthlyFlightStats(ctx context.Context, origin, destination string) (map[string][]*FlightStatsByDateRow, error)
+	FlightStatsByAirline(ctx context.Context, origin, destination string, from, to time.Time) ([]*FlightStats, error)
+	DailyFlightStats(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*FlightStatsByDateRow, error)
+	MonthlyFlightStats(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*FlightStatsByDateRow, error)
 }
 
 type FlightStats struct {
@@ -16,6 +20,13 @@ type FlightStats struct {
 	TotalFlights int
 	TotalDelays  int
 	LastFlight   time.Time
+
+	// Arrival delays in minutes, excluding cancelled and diverted flights.
+	// MedianDelay and P90Delay are estimates.
+	AverageDelay float64
+	MedianDelay  float64
+	P90Delay     float64
+	MaxDelay     int
 }
 
 func (fs *FlightStats) OnTimePercentage() float64 {
@@ -26,12 +37,23 @@ type FlightStatsByDateRow struct {
 	Date    time.Time
 	Flights int
 	Delays  int
+
+	AverageDelay float64
+	MedianDelay  float64
+	P90Delay     float64
+	MaxDelay     int
 }
 
 func (fs *FlightStatsByDateRow) OnTimePercentage() float64 {
 	return calcOnTimePercentage(fs.Flights, fs.Delays)
 }
 
+// calcOnTimePercentage returns the percentage of flights that were on time.
+//
+// delayed must count every flight that wasn't on time, including cancelled and
+// diverted flights. The flights_day rollup follows the Department of
+// Transportation and counts a flight as delayed when it arrives 15 minutes or
+// more late.
 func calcOnTimePercentage(total, delayed int) float64 {
 	if total <= 0 {
 		return 0
@@ -43,3 +65,28 @@ func calcOnTimePercentage(total, delayed int) float64 {
 type OnTimeStat interface {
 	OnTimePercentage() float64
 }
+
+// ParseDate parses a date in YYYY-MM-DD format. An empty string returns a zero
+// time.
+func ParseDate(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, true
+	}
+
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+// IsValidDateRange returns false when both dates are set and to comes before
+// from.
+func IsValidDateRange(from, to time.Time) bool {
+	if from.IsZero() || to.IsZero() {
+		return true
+	}
+
+	return !to.Before(from)
+}
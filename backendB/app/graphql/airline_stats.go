@@ -12,6 +12,10 @@ var airlineFlightStatsType = graphql.NewObject(
 			"totalFlights":     &graphql.Field{Type: graphql.Int},
 			"onTimePercentage": &graphql.Field{Type: graphql.Float, Resolve: resolveOnTimePercentage},
 			"lastFlight":       &graphql.Field{Type: graphql.DateTime},
+			"averageDelay":     &graphql.Field{Type: graphql.Float},
+			"medianDelay":      &graphql.Field{Type: graphql.Float},
+			"p90Delay":         &graphql.Field{Type: graphql.Float},
+			"maxDelay":         &graphql.Field{Type: graphql.Int},
 		},
 	},
 )
@@ -28,6 +32,8 @@ func (p *Processor) flightStatsByAirlineQuery() *graphql.Field {
 				Type:        graphql.String,
 				Description: "airport IATA code (e.g. LAX)",
 			},
+			"from": dateArgument(),
+			"to":   dateArgument(),
 		},
 		Resolve: instrumentResolver("flightstats_by_airline", p.resolveFlightStatsByAirlineQuery),
 	}
@@ -40,5 +46,10 @@ func (p *Processor) resolveFlightStatsByAirlineQuery(params graphql.ResolveParam
 		return nil, nil
 	}
 
-	return p.config.FlightStatsStore.FlightStatsByAirline(params.Context, origin, dest)
+	from, to, ok := p.getDateRangeParams(params)
+	if !ok {
+		return nil, nil
+	}
+
+	return p.config.FlightStatsStore.FlightStatsByAirline(params.Context, origin, dest, from, to)
 }
@@ -15,6 +15,10 @@ var flightStatsByDateRow = graphql.NewObject(
 			"flights":          &graphql.Field{Type: graphql.Int},
 			"delays":           &graphql.Field{Type: graphql.Int},
 			"onTimePercentage": &graphql.Field{Type: graphql.Float, Resolve: resolveOnTimePercentage},
+			"averageDelay":     &graphql.Field{Type: graphql.Float},
+			"medianDelay":      &graphql.Field{Type: graphql.Float},
+			"p90Delay":         &graphql.Field{Type: graphql.Float},
+			"maxDelay":         &graphql.Field{Type: graphql.Int},
 		},
 	},
 )
@@ -43,6 +47,8 @@ func (p *Processor) dailyFlightStatsQuery() *graphql.Field {
 				Type:        graphql.String,
 				Description: "airport IATA code (e.g. LAX)",
 			},
+			"from": dateArgument(),
+			"to":   dateArgument(),
 		},
 		Resolve: instrumentResolver("daily_flight_stats", p.resolveDailyFlightStats),
 	}
@@ -75,7 +81,12 @@ func (p *Processor) resolveDailyFlightStats(params graphql.ResolveParams) (inter
 		return nil, nil
 	}
 
-	statsMap, err := p.config.FlightStatsStore.DailyFlightStats(params.Context, origin, dest)
+	from, to, ok := p.getDateRangeParams(params)
+	if !ok {
+		return nil, nil
+	}
+
+	statsMap, err := p.config.FlightStatsStore.DailyFlightStats(params.Context, origin, dest, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +106,8 @@ func (p *Processor) monthlyFlightStatsQuery() *graphql.Field {
 				Type:        graphql.String,
 				Description: "airport IATA code (e.g. LAX)",
 			},
+			"from": dateArgument(),
+			"to":   dateArgument(),
 		},
 		Resolve: instrumentResolver("monthly_flight_stats", p.resolveMonthlyFlightStats),
 	}
@@ -107,7 +120,12 @@ func (p *Processor) resolveMonthlyFlightStats(params graphql.ResolveParams) (int
 		return nil, nil
 	}
 
-	statsMap, err := p.config.FlightStatsStore.MonthlyFlightStats(params.Context, origin, dest)
+	from, to, ok := p.getDateRangeParams(params)
+	if !ok {
+		return nil, nil
+	}
+
+	statsMap, err := p.config.FlightStatsStore.MonthlyFlightStats(params.Context, origin, dest, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -21,12 +21,19 @@ func TestFlightStatsByAirline(t *testing.T) {
 			query:    `{flightStatsByAirline(origin:"SOX",destination:"SAX"){airline,onTimePercentage}}`,
 			expected: `{"flightStatsByAirline":[{"airline":"Delta","onTimePercentage":90}]}`,
 		},
+		{
+			stats: []*app.FlightStats{
+				{Airline: "Delta", TotalFlights: 100, TotalDelays: 10, AverageDelay: 4.5, MedianDelay: -2, P90Delay: 31.25, MaxDelay: 212},
+			},
+			query:    `{flightStatsByAirline(origin:"SOX",destination:"SAX"){airline,averageDelay,medianDelay,p90Delay,maxDelay}}`,
+			expected: `{"flightStatsByAirline":[{"airline":"Delta","averageDelay":4.5,"maxDelay":212,"medianDelay":-2,"p90Delay":31.25}]}`,
+		},
 	}
 
 	for _, c := range cases {
 		p := NewProcessor(ProcessorConfig{
 			FlightStatsStore: &app.FlightStatsStoreMock{
-				FlightStatsByAirlineFn: func(ctx context.Context, origin, dest string) ([]*app.FlightStats, error) {
+				FlightStatsByAirlineFn: func(ctx context.Context, origin, dest string, from, to time.Time) ([]*app.FlightStats, error) {
 					return c.stats, nil
 				},
 			},
@@ -66,7 +73,7 @@ func TestDailyFlightStats(t *testing.T) {
 	for _, c := range cases {
 		p := NewProcessor(ProcessorConfig{
 			FlightStatsStore: &app.FlightStatsStoreMock{
-				DailyFlightStatsFn: func(ctx context.Context, origin, dest string) (map[string][]*app.FlightStatsByDateRow, error) {
+				DailyFlightStatsFn: func(ctx context.Context, origin, dest string, from, to time.Time) (map[string][]*app.FlightStatsByDateRow, error) {
 					return c.stats, nil
 				},
 			},
@@ -106,7 +113,7 @@ func TestMonthlyFlightStats(t *testing.T) {
 	for _, c := range cases {
 		p := NewProcessor(ProcessorConfig{
 			FlightStatsStore: &app.FlightStatsStoreMock{
-				MonthlyFlightStatsFn: func(ctx context.Context, origin, dest string) (map[string][]*app.FlightStatsByDateRow, error) {
+				MonthlyFlightStatsFn: func(ctx context.Context, origin, dest string, from, to time.Time) (map[string][]*app.FlightStatsByDateRow, error) {
 					return c.stats, nil
 				},
 			},
@@ -124,6 +131,67 @@ func TestMonthlyFlightStats(t *testing.T) {
 	}
 }
 
+func TestFlightStatsDateRange(t *testing.T) {
+	cases := []struct {
+		query        string
+		expectCall   bool
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{
+			query:        `{dailyFlightStats(origin:"SOX",destination:"SAX",from:"2019-01-01",to:"2019-01-31"){airline}}`,
+			expectCall:   true,
+			expectedFrom: date(2019, 01, 01),
+			expectedTo:   date(2019, 01, 31),
+		},
+		{
+			query:      `{dailyFlightStats(origin:"SOX",destination:"SAX",to:"2019-01-31"){airline}}`,
+			expectCall: true,
+			expectedTo: date(2019, 01, 31),
+		},
+		{
+			query:      `{dailyFlightStats(origin:"SOX",destination:"SAX",from:"2019-01-31",to:"2019-01-01"){airline}}`,
+			expectCall: false,
+		},
+		{
+			query:      `{dailyFlightStats(origin:"SOX",destination:"SAX",from:"January"){airline}}`,
+			expectCall: false,
+		},
+	}
+
+	for _, c := range cases {
+		called := false
+
+		p := NewProcessor(ProcessorConfig{
+			FlightStatsStore: &app.FlightStatsStoreMock{
+				DailyFlightStatsFn: func(ctx context.Context, origin, dest string, from, to time.Time) (map[string][]*app.FlightStatsByDateRow, error) {
+					called = true
+
+					if !from.Equal(c.expectedFrom) {
+						t.Errorf("%s: got from %v, want %v", c.query, from, c.expectedFrom)
+					}
+
+					if !to.Equal(c.expectedTo) {
+						t.Errorf("%s: got to %v, want %v", c.query, to, c.expectedTo)
+					}
+
+					return map[string][]*app.FlightStatsByDateRow{}, nil
+				},
+			},
+		})
+
+		_, err := p.Do(context.Background(), c.query)
+		if err != nil {
+			t.Errorf("got error %v, want nil", err)
+			continue
+		}
+
+		if called != c.expectCall {
+			t.Errorf("%s: got called %v, want %v", c.query, called, c.expectCall)
+		}
+	}
+}
+
 func date(year int, month time.Month, day int) time.Time {
 	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/gqlerrors"
@@ -127,6 +128,33 @@ func promRegister(c prometheus.Collector) {
 	prometheus.MustRegister(c)
 }
 
+func dateArgument() *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{
+		Type:        graphql.String,
+		Description: "date in YYYY-MM-DD format (e.g. 2019-01-31)",
+	}
+}
+
+// getDateRangeParams reads the optional "from" and "to" params. Missing params
+// are returned as zero times. ok is false if either param is invalid.
+func (p *Processor) getDateRangeParams(params graphql.ResolveParams) (from, to time.Time, ok bool) {
+	fromStr, _ := params.Args["from"].(string)
+	toStr, _ := params.Args["to"].(string)
+
+	from, ok = app.ParseDate(fromStr)
+	if !ok {
+		return
+	}
+
+	to, ok = app.ParseDate(toStr)
+	if !ok {
+		return
+	}
+
+	ok = app.IsValidDateRange(from, to)
+	return
+}
+
 func resolveOnTimePercentage(params graphql.ResolveParams) (interface{}, error) {
 	stats, ok := params.Source.(app.OnTimeStat)
 	if !ok {
@@ -3,29 +3,35 @@ package mysql
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/pboyd/flightranker-backend/backendb/app"
 )
 
-func (s *Store) FlightStatsByAirline(ctx context.Context, origin, dest string) ([]*app.FlightStats, error) {
+func (s *Store) FlightStatsByAirline(ctx context.Context, origin, dest string, from, to time.Time) ([]*app.FlightStats, error) {
+	dateCond, args := dateRangeCondition(from, to)
+
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT
-			carriers.name AS carrier_name, total_flights, delays_flights, last_flight
+			carriers.name AS carrier_name, total_flights, delays_flights, last_flight,
+			average_delay, max_delay
 		FROM
 			(
 				SELECT
 					carrier AS carrier_code,
 					SUM(total_flights) AS total_flights,
 					SUM(delayed_flights) AS delays_flights,
-					MAX(date) AS last_flight
+					MAX(date) AS last_flight,
+					IFNULL(SUM(sum_arrival_delay) / SUM(arrived_flights), 0) AS average_delay,
+					IFNULL(MAX(max_arrival_delay), 0) AS max_delay
 				FROM
 					flights_day
-				WHERE origin=? AND destination=?
+				WHERE origin=? AND destination=?`+dateCond+`
 				GROUP BY carrier_code
 			) AS stats
 		INNER JOIN carriers ON carrier_code=carriers.code
 		`,
-		origin, dest)
+		append([]interface{}{origin, dest}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +42,7 @@ func (s *Store) FlightStatsByAirline(ctx context.Context, origin, dest string) (
 	for rows.Next() {
 		var row app.FlightStats
 
-		err := rows.Scan(&row.Airline, &row.TotalFlights, &row.TotalDelays, &row.LastFlight)
+		err := rows.Scan(&row.Airline, &row.TotalFlights, &row.TotalDelays, &row.LastFlight, &row.AverageDelay, &row.MaxDelay)
 		if err != nil {
 			return nil, err
 		}
@@ -44,6 +50,17 @@ func (s *Store) FlightStatsByAirline(ctx context.Context, origin, dest string) (
 		stats = append(stats, &row)
 	}
 
+	hists, err := s.delayHistograms(ctx, origin, dest, from, to, "''")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range stats {
+		hist := hists[delayKey{airline: row.Airline}]
+		row.MedianDelay = hist.percentile(50)
+		row.P90Delay = hist.percentile(90)
+	}
+
 	sort.Slice(stats, func(i, j int) bool {
 		return stats[j].OnTimePercentage() < stats[i].OnTimePercentage()
 	})
@@ -2,23 +2,28 @@ package mysql
 
 import (
 	"context"
+	"time"
 
 	"github.com/pboyd/flightranker-backend/backendb/app"
 )
 
-func (s *Store) DailyFlightStats(ctx context.Context, origin, destination string) (map[string][]*app.FlightStatsByDateRow, error) {
+func (s *Store) DailyFlightStats(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*app.FlightStatsByDateRow, error) {
+	dateCond, args := dateRangeCondition(from, to)
+
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT
 			date,
 			carriers.name,
 			total_flights,
-			IF(delayed_flights IS NULL, 0, delayed_flights) AS delay_flights_not_null
+			IF(delayed_flights IS NULL, 0, delayed_flights) AS delay_flights_not_null,
+			IFNULL(sum_arrival_delay / arrived_flights, 0),
+			IFNULL(max_arrival_delay, 0)
 		FROM
 			flights_day
 			INNER JOIN carriers ON carrier=carriers.code
-		WHERE origin=? AND destination=?
+		WHERE origin=? AND destination=?`+dateCond+`
 		ORDER BY date`,
-		origin, destination)
+		append([]interface{}{origin, destination}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +37,7 @@ func (s *Store) DailyFlightStats(ctx context.Context, origin, destination string
 			row     app.FlightStatsByDateRow
 		)
 
-		err := rows.Scan(&row.Date, &airline, &row.Flights, &row.Delays)
+		err := rows.Scan(&row.Date, &airline, &row.Flights, &row.Delays, &row.AverageDelay, &row.MaxDelay)
 		if err != nil {
 			return nil, err
 		}
@@ -44,5 +49,18 @@ func (s *Store) DailyFlightStats(ctx context.Context, origin, destination string
 		stats[airline] = append(stats[airline], &row)
 	}
 
+	hists, err := s.delayHistograms(ctx, origin, destination, from, to, "DATE_FORMAT(date, '%Y-%m-%d')")
+	if err != nil {
+		return nil, err
+	}
+
+	for airline, series := range stats {
+		for _, row := range series {
+			hist := hists[delayKey{airline: airline, period: row.Date.Format("2006-01-02")}]
+			row.MedianDelay = hist.percentile(50)
+			row.P90Delay = hist.percentile(90)
+		}
+	}
+
 	return stats, nil
 }
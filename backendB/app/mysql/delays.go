@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// delayBucketMinutes is the width of the delay_bucket column in
+// flights_day_delays.
+const delayBucketMinutes = 5
+
+type delayKey struct {
+	airline string
+	period  string
+}
+
+// delayHistogram maps the lower bound of each delay bucket to the number of
+// flights in it.
+type delayHistogram map[int]int
+
+// delayHistograms reads arrival delay histograms from flights_day_delays for
+// each airline and period. period is a SQL expression that identifies the time
+// period of a row, and is returned in delayKey.period.
+func (s *Store) delayHistograms(ctx context.Context, origin, destination string, from, to time.Time, period string) (map[delayKey]delayHistogram, error) {
+	dateCond, args := dateRangeCondition(from, to)
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT
+			carriers.name,
+			`+period+` AS period,
+			delay_bucket,
+			SUM(flights)
+		FROM
+			flights_day_delays
+			INNER JOIN carriers ON carrier=carriers.code
+		WHERE origin=? AND destination=?`+dateCond+`
+		GROUP BY carriers.name, period, delay_bucket`,
+		append([]interface{}{origin, destination}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hists := map[delayKey]delayHistogram{}
+
+	for rows.Next() {
+		var (
+			key            delayKey
+			delay, flights int
+		)
+
+		err := rows.Scan(&key.airline, &key.period, &delay, &flights)
+		if err != nil {
+			return nil, err
+		}
+
+		if hists[key] == nil {
+			hists[key] = delayHistogram{}
+		}
+		hists[key][delay] += flights
+	}
+
+	return hists, rows.Err()
+}
+
+// percentile estimates the delay at the pth percentile (0-100), assuming
+// flights are spread evenly through each bucket. An empty histogram returns 0.
+func (h delayHistogram) percentile(p float64) float64 {
+	total := 0
+	buckets := make([]int, 0, len(h))
+	for bucket, count := range h {
+		total += count
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	if total == 0 {
+		return 0
+	}
+
+	target := p / 100 * float64(total)
+	seen := 0.0
+
+	for _, bucket := range buckets {
+		count := float64(h[bucket])
+		if count > 0 && seen+count >= target {
+			return float64(bucket) + (target-seen)/count*delayBucketMinutes
+		}
+		seen += count
+	}
+
+	return float64(buckets[len(buckets)-1] + delayBucketMinutes)
+}
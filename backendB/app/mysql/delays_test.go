@@ -0,0 +1,39 @@
+package mysql
+
+import "testing"
+
+func TestDelayHistogramPercentile(t *testing.T) {
+	cases := []struct {
+		hist     delayHistogram
+		p        float64
+		expected float64
+	}{
+		{
+			hist:     delayHistogram{0: 10},
+			p:        50,
+			expected: 2.5,
+		},
+		{
+			hist:     delayHistogram{-10: 5, 20: 5},
+			p:        50,
+			expected: -5,
+		},
+		{
+			hist:     delayHistogram{-5: 2, 0: 6, 60: 2},
+			p:        90,
+			expected: 62.5,
+		},
+		{
+			hist:     nil,
+			p:        50,
+			expected: 0,
+		},
+	}
+
+	for _, c := range cases {
+		actual := c.hist.percentile(c.p)
+		if actual != c.expected {
+			t.Errorf("%v p%v: got %v, want %v", c.hist, c.p, actual, c.expected)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/pboyd/flightranker-backend/backendb/app"
 	"github.com/pboyd/flightranker-backend/backendtest"
@@ -29,7 +30,7 @@ func TestFlightStatsByAirline(t *testing.T) {
 	store := NewStoreFromDB(backendtest.ConnectMySQL(t))
 
 	for _, c := range cases {
-		actual, err := store.FlightStatsByAirline(context.Background(), c.origin, c.dest)
+		actual, err := store.FlightStatsByAirline(context.Background(), c.origin, c.dest, time.Time{}, time.Time{})
 		if err != nil {
 			t.Errorf("%s-%s: got error %v, want nil", c.origin, c.dest, err)
 			continue
@@ -84,7 +85,7 @@ func TestDailyFlightStats(t *testing.T) {
 	store := NewStoreFromDB(backendtest.ConnectMySQL(t))
 
 	for _, c := range cases {
-		actual, err := store.DailyFlightStats(context.Background(), c.origin, c.dest)
+		actual, err := store.DailyFlightStats(context.Background(), c.origin, c.dest, time.Time{}, time.Time{})
 		if err != nil {
 			t.Errorf("%s-%s: got error %v, want nil", c.origin, c.dest, err)
 			continue
@@ -135,7 +136,7 @@ func TestMonthlyFlightStats(t *testing.T) {
 	store := NewStoreFromDB(backendtest.ConnectMySQL(t))
 
 	for _, c := range cases {
-		actual, err := store.MonthlyFlightStats(context.Background(), c.origin, c.dest)
+		actual, err := store.MonthlyFlightStats(context.Background(), c.origin, c.dest, time.Time{}, time.Time{})
 		if err != nil {
 			t.Errorf("%s-%s: got error %v, want nil", c.origin, c.dest, err)
 			continue
@@ -7,19 +7,23 @@ import (
 	"github.com/pboyd/flightranker-backend/backendb/app"
 )
 
-func (s *Store) MonthlyFlightStats(ctx context.Context, origin, destination string) (map[string][]*app.FlightStatsByDateRow, error) {
+func (s *Store) MonthlyFlightStats(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*app.FlightStatsByDateRow, error) {
+	dateCond, args := dateRangeCondition(from, to)
+
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT
 			YEAR(date) AS year,
 			MONTH(date) AS month,
 			carriers.name,
 			SUM(total_flights),
-			SUM(IF(delayed_flights IS NULL, 0, delayed_flights)) AS delay_flights_not_null
+			SUM(IF(delayed_flights IS NULL, 0, delayed_flights)) AS delay_flights_not_null,
+			IFNULL(SUM(sum_arrival_delay) / SUM(arrived_flights), 0),
+			IFNULL(MAX(max_arrival_delay), 0)
 		FROM
 			flights_day
 			INNER JOIN carriers ON carrier=carriers.code
-		WHERE origin=? AND destination=? GROUP BY year, month, carriers.name`,
-		origin, destination)
+		WHERE origin=? AND destination=?`+dateCond+` GROUP BY year, month, carriers.name`,
+		append([]interface{}{origin, destination}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +38,7 @@ func (s *Store) MonthlyFlightStats(ctx context.Context, origin, destination stri
 			year, month int
 		)
 
-		err := rows.Scan(&year, &month, &airline, &row.Flights, &row.Delays)
+		err := rows.Scan(&year, &month, &airline, &row.Flights, &row.Delays, &row.AverageDelay, &row.MaxDelay)
 		if err != nil {
 			return nil, err
 		}
@@ -48,5 +52,18 @@ func (s *Store) MonthlyFlightStats(ctx context.Context, origin, destination stri
 		stats[airline] = append(stats[airline], &row)
 	}
 
+	hists, err := s.delayHistograms(ctx, origin, destination, from, to, "DATE_FORMAT(date, '%Y-%m')")
+	if err != nil {
+		return nil, err
+	}
+
+	for airline, series := range stats {
+		for _, row := range series {
+			hist := hists[delayKey{airline: airline, period: row.Date.Format("2006-01")}]
+			row.MedianDelay = hist.percentile(50)
+			row.P90Delay = hist.percentile(90)
+		}
+	}
+
 	return stats, nil
 }
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/pboyd/flightranker-backend/backendb/app"
@@ -32,6 +33,26 @@ func NewStoreFromDB(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
+// dateRangeCondition returns SQL to append to a WHERE clause to limit results
+// to the dates between from and to, along with the query arguments. Zero
+// times are ignored.
+func dateRangeCondition(from, to time.Time) (string, []interface{}) {
+	cond := ""
+	args := []interface{}{}
+
+	if !from.IsZero() {
+		cond += " AND date>=?"
+		args = append(args, from.Format("2006-01-02"))
+	}
+
+	if !to.IsZero() {
+		cond += " AND date<=?"
+		args = append(args, to.Format("2006-01-02"))
+	}
+
+	return cond, args
+}
+
 type Config struct {
 	Username string
 	Password string
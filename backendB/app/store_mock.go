@@ -1,6 +1,9 @@
 package app
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 var _ AirportStore = &AirportStoreMock{}
 
@@ -20,19 +23,19 @@ func (m *AirportStoreMock) AirportSearch(ctx context.Context, term string) ([]*A
 var _ FlightStatsStore = &FlightStatsStoreMock{}
 
 type FlightStatsStoreMock struct {
-	FlightStatsByAirlineFn func(ctx context.Context, origin, destination string) ([]*FlightStats, error)
-	DailyFlightStatsFn     func(ctx context.Context, origin, destination string) (map[string][]*FlightStatsByDateRow, error)
-	MonthlyFlightStatsFn   func(ctx context.Context, origin, destination string) (map[string][]*FlightStatsByDateRow, error)
+	FlightStatsByAirlineFn func(ctx context.Context, origin, destination string, from, to time.Time) ([]*FlightStats, error)
+	DailyFlightStatsFn     func(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*FlightStatsByDateRow, error)
+	MonthlyFlightStatsFn   func(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*FlightStatsByDateRow, error)
 }
 
-func (m *FlightStatsStoreMock) FlightStatsByAirline(ctx context.Context, origin, destination string) ([]*FlightStats, error) {
-	return m.FlightStatsByAirlineFn(ctx, origin, destination)
+func (m *FlightStatsStoreMock) FlightStatsByAirline(ctx context.Context, origin, destination string, from, to time.Time) ([]*FlightStats, error) {
+	return m.FlightStatsByAirlineFn(ctx, origin, destination, from, to)
 }
 
-func (m *FlightStatsStoreMock) DailyFlightStats(ctx context.Context, origin, destination string) (map[string][]*FlightStatsByDateRow, error) {
-	return m.DailyFlightStatsFn(ctx, origin, destination)
+func (m *FlightStatsStoreMock) DailyFlightStats(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*FlightStatsByDateRow, error) {
+	return m.DailyFlightStatsFn(ctx, origin, destination, from, to)
 }
 
-func (m *FlightStatsStoreMock) MonthlyFlightStats(ctx context.Context, origin, destination string) (map[string][]*FlightStatsByDateRow, error) {
-	return m.MonthlyFlightStatsFn(ctx, origin, destination)
+func (m *FlightStatsStoreMock) MonthlyFlightStats(ctx context.Context, origin, destination string, from, to time.Time) (map[string][]*FlightStatsByDateRow, error) {
+	return m.MonthlyFlightStatsFn(ctx, origin, destination, from, to)
 }
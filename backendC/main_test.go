@@ -5,16 +5,20 @@ import (
 	"testing"
 
 	"github.com/pboyd/flightranker-backend/backendC/server"
+	"github.com/pboyd/flightranker-backend/backendC/store"
 	"github.com/pboyd/flightranker-backend/backendtest"
 )
 
 var update = flag.Bool("update", false, "update golden files")
 
 func TestStandardQueries(t *testing.T) {
+	st := store.New()
+	defer st.Close()
+
 	runner := &backendtest.Runner{
 		FixturePath: "../testfiles/golden",
 		Update:      *update,
-		Handler:     server.Handler(),
+		Handler:     server.NewHandler(st),
 	}
 
 	runner.RunQuerySet(t, backendtest.StandardTestQueries)
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// aircraftLegType is the GraphQL definition of store.AircraftLeg.
+var aircraftLegType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "aircraftLeg",
+		Fields: graphql.Fields{
+			"leg": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "position of the flight in the aircraft's day, starting at 1",
+			},
+			"carrier":      &graphql.Field{Type: graphql.String},
+			"airline":      &graphql.Field{Type: graphql.String},
+			"flightNumber": &graphql.Field{Type: graphql.String},
+			"origin":       &graphql.Field{Type: graphql.String},
+			"destination":  &graphql.Field{Type: graphql.String},
+			"scheduledDeparture": &graphql.Field{
+				Type:        graphql.String,
+				Description: "local time in hh:mm format",
+			},
+			"scheduledArrival": &graphql.Field{
+				Type:        graphql.String,
+				Description: "local time in hh:mm format",
+			},
+			"departure": &graphql.Field{
+				Type:        graphql.String,
+				Description: "local time in hh:mm format, empty when cancelled",
+			},
+			"arrival": &graphql.Field{
+				Type:        graphql.String,
+				Description: "local time in hh:mm format, empty when cancelled or diverted",
+			},
+			"departureDelay": &graphql.Field{Type: graphql.Int},
+			"arrivalDelay":   &graphql.Field{Type: graphql.Int},
+			"cancelled":      &graphql.Field{Type: graphql.Boolean},
+			"diverted":       &graphql.Field{Type: graphql.Boolean},
+			"lateAircraftDelay": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "minutes of delay caused by the inbound leg",
+			},
+			"inboundLeg": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "leg that brought the aircraft to the origin, 0 if none",
+			},
+		},
+	},
+)
+
+// inboundDelayType is the GraphQL definition of store.InboundDelay.
+var inboundDelayType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "inboundDelay",
+		Fields: graphql.Fields{
+			"origin": &graphql.Field{
+				Type:        graphql.String,
+				Description: "airport the aircraft arrived from",
+			},
+			"flights": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "flights delayed by a late aircraft from origin",
+			},
+			"minutes": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "total late aircraft delay in minutes",
+			},
+			"averageInboundDelay": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "mean arrival delay of the inbound flights in minutes",
+			},
+		},
+	},
+)
+
+// tailHistoryQuery defines the tailHistory GraphQL query, which returns the
+// flights an aircraft flew on a day.
+// The store instance is used when resolving the query.
+func tailHistoryQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(aircraftLegType),
+		Description: "flights by an aircraft on one day",
+		Args: graphql.FieldConfigArgument{
+			"tailNumber": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "aircraft registration (e.g. N123AA)",
+			},
+			"date": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: dateArgument.Description,
+			},
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			tailNumber, _ := params.Args["tailNumber"].(string)
+
+			date, ok := dateArg(params, "date")
+			if !ok {
+				return nil, nil
+			}
+
+			legs, err := st.TailHistory(params.Context, tailNumber, date)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return legs, nil
+		},
+	}
+}
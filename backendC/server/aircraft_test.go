@@ -0,0 +1,22 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailHistoryInvalid(t *testing.T) {
+	queries := []string{
+		`{tailHistory(tailNumber:"N1$",date:"2019-01-01"){leg}}`,
+		`{tailHistory(tailNumber:"N123AA",date:"January 1"){leg}}`,
+	}
+
+	assert := assert.New(t)
+
+	for _, query := range queries {
+		var response map[string]interface{}
+		runTestQuery(t, query, &response)
+		assert.Nil(response["tailHistory"], query)
+	}
+}
@@ -5,20 +5,25 @@ import (
 	"github.com/pboyd/flightranker-backend/backendC/store"
 )
 
-// airportType is the GraphQL definition for an airport.
-var airportType = graphql.NewObject(
-	graphql.ObjectConfig{
-		Name: "Airport",
-		Fields: graphql.Fields{
-			"code":      &graphql.Field{Type: graphql.String},
-			"name":      &graphql.Field{Type: graphql.String},
-			"city":      &graphql.Field{Type: graphql.String},
-			"state":     &graphql.Field{Type: graphql.String},
-			"latitude":  &graphql.Field{Type: graphql.Float},
-			"longitude": &graphql.Field{Type: graphql.Float},
+// newAirportType returns the GraphQL definition for an airport. The store
+// instance is used when resolving the congestion field, so each handler needs
+// its own.
+func newAirportType(st *store.Store) *graphql.Object {
+	return graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "Airport",
+			Fields: graphql.Fields{
+				"code":       &graphql.Field{Type: graphql.String},
+				"name":       &graphql.Field{Type: graphql.String},
+				"city":       &graphql.Field{Type: graphql.String},
+				"state":      &graphql.Field{Type: graphql.String},
+				"latitude":   &graphql.Field{Type: graphql.Float},
+				"longitude":  &graphql.Field{Type: graphql.Float},
+				"congestion": airportCongestionField(st),
+			},
 		},
-	},
-)
+	)
+}
 
 // airportCodeArgument is the graphql definition for an argument that accepts
 // an airport code.
@@ -27,9 +32,17 @@ var airportCodeArgument = &graphql.ArgumentConfig{
 	Description: "airport IATA code (e.g. LAX)",
 }
 
+// locationArgument is the graphql definition for an argument that accepts an
+// airport code or a metro area code.
+var locationArgument = &graphql.ArgumentConfig{
+	Type:        graphql.String,
+	Description: "airport IATA code (e.g. LAX) or metro area code (e.g. NYC)",
+}
+
 // airportQuery defines a GraphQL query that accepts an airport code and
-// responds with information about the airport.
-func airportQuery(st *store.Store) *graphql.Field {
+// responds with information about the airport. airportType is the type
+// returned by newAirportType.
+func airportQuery(st *store.Store, airportType *graphql.Object) *graphql.Field {
 	return &graphql.Field{
 		Type:        airportType,
 		Description: "get airport by code",
@@ -49,9 +62,10 @@ func airportQuery(st *store.Store) *graphql.Field {
 	}
 }
 
-// airportListQuery defines a GraphQL query that accepts an airport code and
-// responds with information about the airport.
-func airportListQuery(st *store.Store) *graphql.Field {
+// airportListQuery defines a GraphQL query that accepts a search term and
+// responds with a page of matching airports, best match first. airportType is
+// the type returned by newAirportType.
+func airportListQuery(st *store.Store, airportType *graphql.Object) *graphql.Field {
 	return &graphql.Field{
 		Type:        graphql.NewList(airportType),
 		Description: "search airports",
@@ -60,12 +74,72 @@ func airportListQuery(st *store.Store) *graphql.Field {
 				Type:        graphql.String,
 				Description: "search term",
 			},
+			"first": &graphql.ArgumentConfig{
+				Type:        graphql.Int,
+				Description: "maximum number of airports to return",
+			},
+			"after": &graphql.ArgumentConfig{
+				Type:        graphql.String,
+				Description: "code of the last airport on the previous page",
+			},
 		},
 		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
 			term, _ := params.Args["term"].(string)
-			airports, err := st.AirportSearch(params.Context, term)
 
-			if err == store.ErrInvalidTerm {
+			var opts store.SearchOpts
+			opts.First, _ = params.Args["first"].(int)
+			opts.After, _ = params.Args["after"].(string)
+
+			airports, err := st.AirportSearch(params.Context, term, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			}
+
+			return airports, err
+		},
+	}
+}
+
+// airportsNearQuery defines a GraphQL query that accepts a location and
+// responds with the nearest airports. airportType is the type returned by
+// newAirportType.
+func airportsNearQuery(st *store.Store, airportType *graphql.Object) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewList(graphql.NewObject(
+			graphql.ObjectConfig{
+				Name: "NearbyAirport",
+				Fields: graphql.Fields{
+					"airport": &graphql.Field{Type: airportType},
+					"distanceKm": &graphql.Field{
+						Type:        graphql.Float,
+						Description: "great-circle distance in kilometers",
+					},
+				},
+			},
+		)),
+		Description: "find airports near a location, nearest first",
+		Args: graphql.FieldConfigArgument{
+			"latitude":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"longitude": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"radiusKm": &graphql.ArgumentConfig{
+				Type:         graphql.Float,
+				DefaultValue: 100.0,
+			},
+			"limit": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: 10,
+			},
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			lat, _ := params.Args["latitude"].(float64)
+			lng, _ := params.Args["longitude"].(float64)
+			radius, _ := params.Args["radiusKm"].(float64)
+			limit, _ := params.Args["limit"].(int)
+
+			airports, err := st.AirportsNear(params.Context, lat, lng, radius, limit)
+
+			if isInvalidInput(err) {
 				return nil, nil
 			}
 
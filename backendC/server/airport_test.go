@@ -50,6 +50,14 @@ func TestAirportList(t *testing.T) {
 			query:         `{airportList(term:"vegas"){code}}`,
 			expectedCodes: []string{"LAS"},
 		},
+		{
+			query:         `{airportList(term:"las",first:1){code}}`,
+			expectedCodes: []string{"LAS"},
+		},
+		{
+			query:         `{airportList(term:"vegas",after:"LAS"){code}}`,
+			expectedCodes: []string{},
+		},
 	}
 
 	assert := assert.New(t)
@@ -66,3 +74,19 @@ func TestAirportList(t *testing.T) {
 		assert.Equal(c.expectedCodes, actualCodes)
 	}
 }
+
+func TestAirportsNear(t *testing.T) {
+	var response map[string][]store.NearbyAirport
+	runTestQuery(t, `{airportsNear(latitude:36.17,longitude:-115.14,limit:3){distanceKm,airport{code}}}`, &response)
+
+	assert := assert.New(t)
+
+	actual := response["airportsNear"]
+	if !assert.NotEmpty(actual) {
+		return
+	}
+
+	assert.LessOrEqual(len(actual), 3)
+	assert.Equal("LAS", actual[0].Airport.Code)
+	assert.Greater(actual[0].Distance, 0.0)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// timeWindowType is the GraphQL definition of store.TimeWindow.
+var timeWindowType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "timeWindow",
+		Fields: graphql.Fields{
+			"carrier": &graphql.Field{Type: graphql.String, Description: "airline code"},
+			"airline": &graphql.Field{Type: graphql.String},
+			"dayOfWeek": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "day of the week of the scheduled departure (0 is Sunday)",
+			},
+			"hour": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "hour of the scheduled departure (0-23)",
+			},
+			"stats": &graphql.Field{
+				Type:        flightStatsByDateRowType,
+				Description: "totals for the window, flights is the sample size",
+			},
+		},
+	},
+)
+
+// bestTimesToFlyQuery defines the bestTimesToFly GraphQL query, which ranks
+// the departure times between two airports.
+// The store instance is used when resolving the query.
+func bestTimesToFlyQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(timeWindowType),
+		Description: "rank each airline's departure hours and days of the week by on-time percentage",
+		Args: graphql.FieldConfigArgument{
+			"origin":      locationArgument,
+			"destination": locationArgument,
+			"from":        dateArgument,
+			"to":          dateArgument,
+			"minFlights": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: store.DefaultBestTimesMinFlights,
+				Description:  "exclude windows with fewer flights",
+			},
+			"limit": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: 10,
+			},
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			origin, _ := params.Args["origin"].(string)
+			dest, _ := params.Args["destination"].(string)
+
+			var (
+				opts store.BestTimesOpts
+				ok   bool
+			)
+			opts.From, opts.To, ok = dateRangeArgs(params)
+			if !ok {
+				return nil, nil
+			}
+			opts.OnTimeThreshold, _ = params.Args["onTimeThresholdMinutes"].(int)
+			opts.MinFlights, _ = params.Args["minFlights"].(int)
+			opts.Limit, _ = params.Args["limit"].(int)
+
+			windows, err := st.BestTimesToFly(params.Context, origin, dest, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return windows, nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pboyd/flightranker-backend/backendC/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBestTimesToFly(t *testing.T) {
+	var response map[string][]store.TimeWindow
+	runTestQuery(t, `{bestTimesToFly(origin:"LAS",destination:"JFK",limit:5){carrier,airline,dayOfWeek,hour,stats{flights,onTimePercentage,averageDelay}}}`, &response)
+
+	assert := assert.New(t)
+	windows := response["bestTimesToFly"]
+	assert.NotEmpty(windows)
+	assert.LessOrEqual(len(windows), 5)
+
+	for _, w := range windows {
+		assert.NotEmpty(w.Airline)
+		assert.GreaterOrEqual(w.Stats.Flights, store.DefaultBestTimesMinFlights)
+	}
+}
+
+func TestBestTimesToFlyInvalid(t *testing.T) {
+	var response map[string]interface{}
+	runTestQuery(t, `{bestTimesToFly(origin:"LAS",destination:"JFK",limit:-1){hour}}`, &response)
+	assert.Nil(t, response["bestTimesToFly"])
+}
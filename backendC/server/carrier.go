@@ -0,0 +1,53 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// carrierType is the GraphQL definition for an airline.
+var carrierType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "Carrier",
+		Fields: graphql.Fields{
+			"code": &graphql.Field{Type: graphql.String},
+			"name": &graphql.Field{Type: graphql.String},
+		},
+	},
+)
+
+// carrierQuery defines a GraphQL query that accepts a carrier code and
+// responds with information about the airline.
+func carrierQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        carrierType,
+		Description: "get airline by code",
+		Args: graphql.FieldConfigArgument{
+			"code": &graphql.ArgumentConfig{
+				Type:        graphql.String,
+				Description: "airline code (e.g. WN)",
+			},
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			code, _ := params.Args["code"].(string)
+			carrier, err := st.Carrier(params.Context, code)
+
+			if err == store.ErrInvalidCarrierCode {
+				return nil, nil
+			}
+
+			return carrier, err
+		},
+	}
+}
+
+// carriersQuery defines a GraphQL query that lists every airline.
+func carriersQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(carrierType),
+		Description: "list airlines",
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			return st.Carriers(params.Context)
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pboyd/flightranker-backend/backendC/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarrier(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected store.Carrier
+	}{
+		{
+			query:    `{carrier(code:"B6"){code,name}}`,
+			expected: store.Carrier{Code: "B6", Name: "JetBlue Airways"},
+		},
+		{
+			query:    `{carrier(code:"ZZ"){code,name}}`,
+			expected: store.Carrier{},
+		},
+		{
+			query:    `{carrier(code:"$"){code,name}}`,
+			expected: store.Carrier{},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string]store.Carrier
+		runTestQuery(t, c.query, &response)
+		assert.Equal(c.expected, response["carrier"])
+	}
+}
+
+func TestCarriers(t *testing.T) {
+	var response map[string][]store.Carrier
+	runTestQuery(t, `{carriers{code,name}}`, &response)
+
+	assert := assert.New(t)
+	assert.Contains(response["carriers"], store.Carrier{Code: "B6", Name: "JetBlue Airways"})
+}
+
+func TestFlightStatsCarrierCode(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK"){code,airline}}`, &response)
+
+	assert := assert.New(t)
+	assert.Contains(response["flightStatsByAirline"], flightStatsByAirlineRow{Code: "B6", Airline: "JetBlue Airways"})
+}
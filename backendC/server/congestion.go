@@ -0,0 +1,150 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// taxiTimesType is the GraphQL definition of store.TaxiTimes.
+var taxiTimesType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "taxiTimes",
+		Fields: graphql.Fields{
+			"flights": &graphql.Field{Type: graphql.Int},
+			"average": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "mean taxi time in minutes",
+			},
+			"p90": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "estimated 90th percentile taxi time in minutes",
+			},
+		},
+	},
+)
+
+// congestionType is the GraphQL definition of store.AirportCongestion.
+var congestionType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "airportCongestion",
+		Fields: graphql.Fields{
+			"byHour": &graphql.Field{
+				Type: graphql.NewList(graphql.NewObject(
+					graphql.ObjectConfig{
+						Name: "hourlyCongestion",
+						Fields: graphql.Fields{
+							"carrier": &graphql.Field{Type: graphql.String, Description: "airline code"},
+							"airline": &graphql.Field{Type: graphql.String},
+							"hour": &graphql.Field{
+								Type:        graphql.Int,
+								Description: "hour (0-23) of the scheduled departure for taxiOut and scheduled arrival for taxiIn",
+							},
+							"taxiOut": &graphql.Field{Type: taxiTimesType},
+							"taxiIn":  &graphql.Field{Type: taxiTimesType},
+						},
+					},
+				)),
+				Description: "taxi times for each airline and hour of the day",
+			},
+			"byMonth": &graphql.Field{
+				Type: graphql.NewList(graphql.NewObject(
+					graphql.ObjectConfig{
+						Name: "monthlyCongestion",
+						Fields: graphql.Fields{
+							"carrier": &graphql.Field{Type: graphql.String, Description: "airline code"},
+							"airline": &graphql.Field{Type: graphql.String},
+							"month": &graphql.Field{
+								Type:        graphql.DateTime,
+								Description: "first day of the month",
+							},
+							"taxiOut": &graphql.Field{Type: taxiTimesType},
+							"taxiIn":  &graphql.Field{Type: taxiTimesType},
+						},
+					},
+				)),
+				Description: "taxi times for each airline and month",
+			},
+			"volume": &graphql.Field{
+				Type: graphql.NewList(graphql.NewObject(
+					graphql.ObjectConfig{
+						Name: "hourlyVolume",
+						Fields: graphql.Fields{
+							"hour":       &graphql.Field{Type: graphql.Int},
+							"departures": &graphql.Field{Type: graphql.Int},
+							"arrivals":   &graphql.Field{Type: graphql.Int},
+						},
+					},
+				)),
+				Description: "scheduled flights in each hour of the day",
+			},
+		},
+	},
+)
+
+// congestionArgs are the arguments to airportCongestionQuery and
+// airportCongestionField.
+var congestionArgs = graphql.FieldConfigArgument{
+	"from": dateArgument,
+	"to":   dateArgument,
+}
+
+// airportCongestionQuery defines the airportCongestion GraphQL query, which
+// returns taxi times and traffic for an airport.
+// The store instance is used when resolving the query.
+func airportCongestionQuery(st *store.Store) *graphql.Field {
+	args := graphql.FieldConfigArgument{"code": airportCodeArgument}
+	for name, arg := range congestionArgs {
+		args[name] = arg
+	}
+
+	return &graphql.Field{
+		Type:        congestionType,
+		Description: "get taxi times and traffic for an airport",
+		Args:        args,
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			code, _ := params.Args["code"].(string)
+			return resolveCongestion(params, st, code)
+		},
+	}
+}
+
+// airportCongestionField defines the congestion field of an airport.
+// The store instance is used when resolving the field.
+func airportCongestionField(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        congestionType,
+		Description: "taxi times and traffic",
+		Args:        congestionArgs,
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			airport, ok := params.Source.(*store.Airport)
+			if !ok || airport == nil {
+				return nil, nil
+			}
+
+			return resolveCongestion(params, st, airport.Code)
+		},
+	}
+}
+
+// resolveCongestion returns the congestion for the airport with code, using
+// the date range in params.
+func resolveCongestion(params graphql.ResolveParams, st *store.Store, code string) (interface{}, error) {
+	var (
+		opts store.CongestionOpts
+		ok   bool
+	)
+	opts.From, opts.To, ok = dateRangeArgs(params)
+	if !ok {
+		return nil, nil
+	}
+
+	congestion, err := st.AirportCongestion(params.Context, code, opts)
+
+	if isInvalidInput(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return congestion, nil
+}
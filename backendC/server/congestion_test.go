@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pboyd/flightranker-backend/backendC/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirportCongestion(t *testing.T) {
+	var response map[string]store.AirportCongestion
+	runTestQuery(t, `{airportCongestion(code:"LAS"){byHour{carrier,hour,taxiOut{flights,average,p90}},byMonth{month},volume{hour,departures,arrivals}}}`, &response)
+
+	assert := assert.New(t)
+	congestion := response["airportCongestion"]
+	assert.NotEmpty(congestion.ByHour)
+	assert.NotEmpty(congestion.ByMonth)
+	assert.NotEmpty(congestion.Volume)
+}
+
+func TestAirportCongestionField(t *testing.T) {
+	var response map[string]struct {
+		Code       string                  `json:"code"`
+		Congestion store.AirportCongestion `json:"congestion"`
+	}
+	runTestQuery(t, `{airport(code:"LAS"){code,congestion(from:"2019-01-01",to:"2019-01-31"){byMonth{carrier,month,taxiIn{average}}}}}`, &response)
+
+	assert := assert.New(t)
+	airport := response["airport"]
+	assert.Equal("LAS", airport.Code)
+
+	if assert.NotEmpty(airport.Congestion.ByMonth) {
+		for _, row := range airport.Congestion.ByMonth {
+			assert.Equal(2019, row.Month.Year())
+			assert.Equal(1, int(row.Month.Month()))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// connectionType is the GraphQL definition of store.Connection.
+var connectionType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "connection",
+		Fields: graphql.Fields{
+			"hub": &graphql.Field{
+				Type:        graphql.String,
+				Description: "airport where passengers connect",
+			},
+			"first": &graphql.Field{
+				Type:        flightStatsByDateRowType,
+				Description: "totals for flights from the origin to the hub",
+			},
+			"second": &graphql.Field{
+				Type:        flightStatsByDateRowType,
+				Description: "totals for flights from the hub to the destination",
+			},
+			"onTimePercentage": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "percentage chance that both flights are on time",
+			},
+			"connectionDays": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "days with a scheduled connection that meets the minimum connection time",
+			},
+			"feasible": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "true if the schedule allowed a connection on at least one day",
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					c, ok := params.Source.(*store.Connection)
+					if !ok {
+						return false, nil
+					}
+
+					return c.Feasible(), nil
+				},
+			},
+		},
+	},
+)
+
+// connectingRoutesQuery defines the connectingRoutes GraphQL query, which
+// ranks the hubs that connect two airports.
+// The store instance is used when resolving the query.
+func connectingRoutesQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(connectionType),
+		Description: "rank one-stop itineraries between two airports",
+		Args: graphql.FieldConfigArgument{
+			"origin":      locationArgument,
+			"destination": locationArgument,
+			"from":        dateArgument,
+			"to":          dateArgument,
+			"maxStops": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: 1,
+				Description:  "only 1 is supported",
+			},
+			"minConnectionMinutes": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: store.DefaultMinConnectionTime,
+				Description:  "least number of minutes between scheduled arrival and departure at the hub",
+			},
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			origin, _ := params.Args["origin"].(string)
+			dest, _ := params.Args["destination"].(string)
+
+			var (
+				opts store.ConnectionOpts
+				ok   bool
+			)
+			opts.From, opts.To, ok = dateRangeArgs(params)
+			if !ok {
+				return nil, nil
+			}
+			opts.OnTimeThreshold, _ = params.Args["onTimeThresholdMinutes"].(int)
+			if minutes, ok := params.Args["minConnectionMinutes"].(int); ok {
+				opts.MinConnectionTime = &minutes
+			}
+			opts.MaxStops, _ = params.Args["maxStops"].(int)
+
+			connections, err := st.ConnectingRoutes(params.Context, origin, dest, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return connections, nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectingRoutes(t *testing.T) {
+	var response map[string][]struct {
+		Hub              string  `json:"hub"`
+		Feasible         bool    `json:"feasible"`
+		OnTimePercentage float64 `json:"onTimePercentage"`
+	}
+	runTestQuery(t, `{connectingRoutes(origin:"LAS",destination:"JFK"){hub,feasible,onTimePercentage}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["connectingRoutes"])
+
+	for _, c := range response["connectingRoutes"] {
+		assert.NotEmpty(c.Hub)
+		assert.True(c.OnTimePercentage >= 0 && c.OnTimePercentage <= 100)
+	}
+}
+
+func TestConnectingRoutesNoMinConnection(t *testing.T) {
+	type connection struct {
+		Hub            string `json:"hub"`
+		ConnectionDays int    `json:"connectionDays"`
+	}
+
+	var defaults, noMinimum map[string][]connection
+	runTestQuery(t, `{connectingRoutes(origin:"LAS",destination:"JFK"){hub,connectionDays}}`, &defaults)
+	runTestQuery(t, `{connectingRoutes(origin:"LAS",destination:"JFK",minConnectionMinutes:0){hub,connectionDays}}`, &noMinimum)
+
+	days := map[string]int{}
+	for _, c := range defaults["connectingRoutes"] {
+		days[c.Hub] = c.ConnectionDays
+	}
+
+	// An explicit zero is no minimum rather than the 45 minute default.
+	assert := assert.New(t)
+	assert.NotEmpty(noMinimum["connectingRoutes"])
+	for _, c := range noMinimum["connectingRoutes"] {
+		assert.GreaterOrEqual(c.ConnectionDays, days[c.Hub], c.Hub)
+	}
+}
+
+func TestConnectingRoutesInvalid(t *testing.T) {
+	queries := []string{
+		`{connectingRoutes(origin:"LAS",destination:"JFK",maxStops:0){hub}}`,
+		`{connectingRoutes(origin:"LAS",destination:"JFK",maxStops:2){hub}}`,
+		`{connectingRoutes(origin:"LAS",destination:"JFK",minConnectionMinutes:-10){hub}}`,
+	}
+
+	assert := assert.New(t)
+
+	for _, query := range queries {
+		var response map[string]interface{}
+		runTestQuery(t, query, &response)
+		assert.Nil(response["connectingRoutes"], query)
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// disruptionType is the GraphQL definition of store.Disruption.
+var disruptionType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "disruption",
+		Fields: graphql.Fields{
+			"state": &graphql.Field{Type: graphql.String},
+			"start": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "first disrupted day",
+			},
+			"end": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "last disrupted day",
+			},
+			"airports": &graphql.Field{
+				Type:        graphql.NewList(graphql.String),
+				Description: "codes of the disrupted airports",
+			},
+			"days": &graphql.Field{
+				Type: graphql.NewList(graphql.NewObject(
+					graphql.ObjectConfig{
+						Name: "disruptedDay",
+						Fields: graphql.Fields{
+							"airport": &graphql.Field{Type: graphql.String},
+							"date":    &graphql.Field{Type: graphql.DateTime},
+							"stats": &graphql.Field{
+								Type:        flightStatsByDateRowType,
+								Description: "totals for every airline's departures from the airport",
+							},
+							"baseline": &graphql.Field{
+								Type:        graphql.Float,
+								Description: "mean on-time percentage of the days before",
+							},
+							"stdDev": &graphql.Field{
+								Type:        graphql.Float,
+								Description: "standard deviation of the on-time percentage of the days before",
+							},
+							"score": &graphql.Field{
+								Type:        graphql.Float,
+								Description: "standard deviations from the baseline",
+							},
+							"cause": &graphql.Field{
+								Type:        graphql.String,
+								Description: "cause that delayed or cancelled the most flights: carrier, weather, nas, security, lateAircraft or diverted",
+							},
+						},
+					},
+				)),
+				Description: "disrupted days at each airport",
+			},
+		},
+	},
+)
+
+// disruptionsQuery defines the disruptions GraphQL query, which finds days
+// with on-time percentages far below normal.
+// The store instance is used when resolving the query.
+func disruptionsQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(disruptionType),
+		Description: "find days when airports' on-time percentages were more than 3 standard deviations below their baselines, grouped into events by state",
+		Args: graphql.FieldConfigArgument{
+			"code": &graphql.ArgumentConfig{
+				Type:        graphql.String,
+				Description: "airport code (e.g. DEN), metro area code (e.g. NYC) or state code (e.g. CO)",
+			},
+			"from": dateArgument,
+			"to":   dateArgument,
+			"baselineDays": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: store.DefaultDisruptionBaselineDays,
+				Description:  "number of days before each day in its baseline",
+			},
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			code, _ := params.Args["code"].(string)
+
+			var (
+				opts store.DisruptionOpts
+				ok   bool
+			)
+			opts.From, opts.To, ok = dateRangeArgs(params)
+			if !ok {
+				return nil, nil
+			}
+			opts.BaselineDays, _ = params.Args["baselineDays"].(int)
+
+			disruptions, err := st.Disruptions(params.Context, code, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return disruptions, nil
+		},
+	}
+}
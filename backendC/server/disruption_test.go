@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pboyd/flightranker-backend/backendC/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisruptions(t *testing.T) {
+	var response map[string][]*store.Disruption
+	runTestQuery(t, `{disruptions(code:"NV",from:"2019-01-01",to:"2019-12-31"){state,start,end,airports,days{airport,date,stats{flights,onTimePercentage},baseline,stdDev,score,cause}}}`, &response)
+
+	assert := assert.New(t)
+	for _, d := range response["disruptions"] {
+		assert.Equal("NV", d.State)
+		assert.NotEmpty(d.Airports)
+
+		for _, day := range d.Days {
+			assert.Contains(d.Airports, day.Airport)
+			assert.Greater(day.Stats.Flights, 0)
+			assert.Less(day.Score, 0.0)
+		}
+	}
+}
+
+func TestDisruptionsInvalid(t *testing.T) {
+	for _, query := range []string{
+		`{disruptions(code:"Nevada"){state}}`,
+		`{disruptions(code:"NV",baselineDays:-1){state}}`,
+		`{disruptions(code:"LAS",from:"2019-03-01",to:"2019-01-01"){state}}`,
+	} {
+		var response map[string][]*store.Disruption
+		runTestQuery(t, query, &response)
+		assert.Nil(t, response["disruptions"], query)
+	}
+}
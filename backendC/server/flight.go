@@ -0,0 +1,87 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// flightLegType is the GraphQL definition of store.FlightLeg.
+var flightLegType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "flightLeg",
+		Fields: graphql.Fields{
+			"origin":      &graphql.Field{Type: graphql.String},
+			"destination": &graphql.Field{Type: graphql.String},
+			"summary": &graphql.Field{
+				Type:        flightStatsByDateRowType,
+				Description: "totals for the whole period",
+			},
+			"daily": &graphql.Field{
+				Type:        graphql.NewList(flightStatsByDateRowType),
+				Description: "stats for each day the leg was scheduled",
+			},
+			"inboundDelays": &graphql.Field{
+				Type:        graphql.NewList(inboundDelayType),
+				Description: "late aircraft delays by the airport the aircraft arrived from",
+			},
+		},
+	},
+)
+
+// flightNumberStatsQuery defines the flightNumberStats GraphQL query, which
+// returns the history of a scheduled flight.
+// The store instance is used when resolving the query.
+func flightNumberStatsQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewObject(
+			graphql.ObjectConfig{
+				Name: "flightNumberStats",
+				Fields: graphql.Fields{
+					"carrier":      &graphql.Field{Type: graphql.String},
+					"airline":      &graphql.Field{Type: graphql.String},
+					"flightNumber": &graphql.Field{Type: graphql.String},
+					"legs":         &graphql.Field{Type: graphql.NewList(flightLegType)},
+				},
+			},
+		),
+		Description: "delay history for a flight number",
+		Args: graphql.FieldConfigArgument{
+			"carrier": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "airline code (e.g. UA)",
+			},
+			"flightNumber": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "flight number without the airline code (e.g. 1234)",
+			},
+			"from": dateArgument,
+			"to":   dateArgument,
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			carrier, _ := params.Args["carrier"].(string)
+			number, _ := params.Args["flightNumber"].(string)
+
+			opts, ok := flightStatsOptsArgs(params, store.GroupByAvailable)
+			if !ok {
+				return nil, nil
+			}
+
+			stats, err := st.FlightNumberStats(params.Context, carrier, number, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			if stats == nil {
+				// A nil *FlightNumberStats isn't a nil interface.
+				return nil, nil
+			}
+
+			return stats, nil
+		},
+	}
+}
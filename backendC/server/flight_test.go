@@ -0,0 +1,24 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlightNumberStatsInvalid(t *testing.T) {
+	queries := []string{
+		`{flightNumberStats(carrier:"U$",flightNumber:"123"){airline}}`,
+		`{flightNumberStats(carrier:"UA",flightNumber:"ABC"){airline}}`,
+		`{flightNumberStats(carrier:"ZZ",flightNumber:"9999"){airline}}`,
+		`{flightNumberStats(carrier:"UA",flightNumber:"123",from:"2019-02-01",to:"2019-01-01"){airline}}`,
+	}
+
+	assert := assert.New(t)
+
+	for _, query := range queries {
+		var response map[string]interface{}
+		runTestQuery(t, query, &response)
+		assert.Nil(response["flightNumberStats"], query)
+	}
+}
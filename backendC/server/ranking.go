@@ -0,0 +1,192 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// airlineRankingRow is one row in a response from airlineRankingsQuery.
+type airlineRankingRow struct {
+	Rank             int     `json:"rank"`
+	Code             string  `json:"code"`
+	Airline          string  `json:"airline"`
+	Flights          int     `json:"totalFlights"`
+	OnTimePercentage float64 `json:"onTimePercentage"`
+	OnTimeLowerBound float64 `json:"onTimeLowerBound"`
+	OnTimeUpperBound float64 `json:"onTimeUpperBound"`
+	CancellationRate float64 `json:"cancellationRate"`
+	AverageDelay     float64 `json:"averageDelay"`
+	SchedulePadding  float64 `json:"schedulePadding"`
+	Reliability      float64 `json:"reliability"`
+}
+
+// rankByEnum is the GraphQL definition of store.RankBy.
+var rankByEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "RankBy",
+	Values: graphql.EnumValueConfigMap{
+		"ON_TIME": &graphql.EnumValueConfig{
+			Value:       store.RankByOnTime,
+			Description: "highest on-time percentage first",
+		},
+		"CANCELLATION_RATE": &graphql.EnumValueConfig{
+			Value:       store.RankByCancellationRate,
+			Description: "lowest cancellation rate first",
+		},
+		"AVERAGE_DELAY": &graphql.EnumValueConfig{
+			Value:       store.RankByAverageDelay,
+			Description: "lowest average arrival delay first",
+		},
+		"ON_TIME_LOWER_BOUND": &graphql.EnumValueConfig{
+			Value:       store.RankByOnTimeLowerBound,
+			Description: "highest lower confidence bound of the on-time percentage first",
+		},
+		"RELIABILITY": &graphql.EnumValueConfig{
+			Value:       store.RankByReliability,
+			Description: "highest reliability index first",
+		},
+	},
+})
+
+// reliabilityField is the GraphQL field for the reliability index. The source
+// must have a field with a matching name or json tag.
+var reliabilityField = &graphql.Field{
+	Type:        graphql.Float,
+	Description: "reliability index from 0 to 100, combining the on-time percentage, average delay, cancellation rate and diversion rate",
+}
+
+// reliabilityWeightsArgument is the GraphQL definition for an argument that
+// accepts store.ReliabilityWeights.
+var reliabilityWeightsArgument = &graphql.ArgumentConfig{
+	Type: graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ReliabilityWeights",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"onTime": &graphql.InputObjectFieldConfig{
+				Type:         graphql.Float,
+				DefaultValue: store.DefaultReliabilityWeights.OnTime,
+			},
+			"averageDelay": &graphql.InputObjectFieldConfig{
+				Type:         graphql.Float,
+				DefaultValue: store.DefaultReliabilityWeights.AverageDelay,
+			},
+			"cancellation": &graphql.InputObjectFieldConfig{
+				Type:         graphql.Float,
+				DefaultValue: store.DefaultReliabilityWeights.Cancellation,
+			},
+			"diversion": &graphql.InputObjectFieldConfig{
+				Type:         graphql.Float,
+				DefaultValue: store.DefaultReliabilityWeights.Diversion,
+			},
+		},
+	}),
+	Description: "non-negative weights of each component of the reliability index, only their proportions matter",
+}
+
+// reliabilityWeightsArg reads the optional "reliabilityWeights" argument.
+//
+// ok is false if any weight is negative.
+func reliabilityWeightsArg(params graphql.ResolveParams) (weights store.ReliabilityWeights, ok bool) {
+	fields, _ := params.Args["reliabilityWeights"].(map[string]interface{})
+	weights.OnTime, _ = fields["onTime"].(float64)
+	weights.AverageDelay, _ = fields["averageDelay"].(float64)
+	weights.Cancellation, _ = fields["cancellation"].(float64)
+	weights.Diversion, _ = fields["diversion"].(float64)
+
+	return weights, weights.IsValid()
+}
+
+// airlineRankingsQuery defines the airlineRankings GraphQL query, which ranks
+// airlines across every route.
+// The store instance is used when resolving the query.
+func airlineRankingsQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewList(
+			graphql.NewObject(graphql.ObjectConfig{
+				Name: "airlineRanking",
+				Fields: graphql.Fields{
+					"rank":             &graphql.Field{Type: graphql.Int},
+					"code":             &graphql.Field{Type: graphql.String},
+					"airline":          &graphql.Field{Type: graphql.String},
+					"totalFlights":     &graphql.Field{Type: graphql.Int},
+					"onTimePercentage": &graphql.Field{Type: graphql.Float},
+					"onTimeLowerBound": onTimeLowerBoundField,
+					"onTimeUpperBound": onTimeUpperBoundField,
+					"cancellationRate": &graphql.Field{
+						Type:        graphql.Float,
+						Description: "percentage of flights cancelled",
+					},
+					"averageDelay": &graphql.Field{
+						Type:        graphql.Float,
+						Description: "mean arrival delay in minutes",
+					},
+					"schedulePadding": &graphql.Field{
+						Type:        graphql.Float,
+						Description: "mean minutes the scheduled flight time exceeded the actual flight time",
+					},
+					"reliability": reliabilityField,
+				},
+			}),
+		),
+		Description: "rank airlines across all routes",
+		Args: graphql.FieldConfigArgument{
+			"from": dateArgument,
+			"to":   dateArgument,
+			"state": &graphql.ArgumentConfig{
+				Type:        graphql.String,
+				Description: "only include flights departing from this state (e.g. CO)",
+			},
+			"minFlights": &graphql.ArgumentConfig{
+				Type:        graphql.Int,
+				Description: "exclude airlines with fewer flights",
+			},
+			"rankBy": &graphql.ArgumentConfig{
+				Type:         rankByEnum,
+				DefaultValue: store.RankByOnTime,
+			},
+			"reliabilityWeights": reliabilityWeightsArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			from, to, ok := dateRangeArgs(params)
+			if !ok {
+				return nil, nil
+			}
+
+			opts := store.RankingOpts{From: from, To: to}
+			opts.State, _ = params.Args["state"].(string)
+			opts.MinFlights, _ = params.Args["minFlights"].(int)
+			opts.RankBy, _ = params.Args["rankBy"].(store.RankBy)
+
+			opts.ReliabilityWeights, ok = reliabilityWeightsArg(params)
+			if !ok {
+				return nil, nil
+			}
+
+			rankings, err := st.AirlineRankings(params.Context, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			out := make([]airlineRankingRow, 0, len(rankings))
+			for _, r := range rankings {
+				lower, upper := r.Stats.OnTimeBounds()
+				out = append(out, airlineRankingRow{
+					Rank:             r.Rank,
+					Code:             r.Code,
+					Airline:          r.Airline,
+					Flights:          r.Stats.Flights,
+					OnTimePercentage: r.Stats.OnTime(),
+					OnTimeLowerBound: lower,
+					OnTimeUpperBound: upper,
+					CancellationRate: r.Stats.CancellationRate(),
+					AverageDelay:     r.Stats.AverageDelay,
+					SchedulePadding:  r.Stats.SchedulePadding,
+					Reliability:      r.Stats.Reliability(opts.ReliabilityWeights),
+				})
+			}
+
+			return out, nil
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirlineRankings(t *testing.T) {
+	cases := []struct {
+		query       string
+		expectEmpty bool
+	}{
+		{query: `{airlineRankings{rank,code,airline,totalFlights,onTimePercentage}}`},
+		{query: `{airlineRankings(rankBy:CANCELLATION_RATE,state:"NV",minFlights:1000){rank,code,cancellationRate}}`},
+		{query: `{airlineRankings(from:"2019-02-01",to:"2019-02-28",rankBy:AVERAGE_DELAY){rank,code,averageDelay}}`},
+		{query: `{airlineRankings(rankBy:RELIABILITY,reliabilityWeights:{diversion:0}){rank,code,reliability}}`},
+		{
+			query:       `{airlineRankings(state:"Nevada"){rank,code}}`,
+			expectEmpty: true,
+		},
+		{
+			query:       `{airlineRankings(minFlights:-1){rank,code}}`,
+			expectEmpty: true,
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string][]airlineRankingRow
+		runTestQuery(t, c.query, &response)
+
+		rankings := response["airlineRankings"]
+		if c.expectEmpty {
+			assert.Empty(rankings)
+			continue
+		}
+
+		if !assert.NotEmpty(rankings) {
+			continue
+		}
+
+		for i, r := range rankings {
+			assert.Equal(i+1, r.Rank)
+			assert.NotEmpty(r.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// newRouteType returns the GraphQL definition for a route. airportType is the
+// type returned by newAirportType.
+func newRouteType(airportType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "Route",
+			Fields: graphql.Fields{
+				"destination": &graphql.Field{Type: airportType},
+				"flights":     &graphql.Field{Type: graphql.Int},
+				"carriers":    &graphql.Field{Type: graphql.NewList(graphql.String)},
+				"lastFlight":  &graphql.Field{Type: graphql.DateTime},
+				"distanceMiles": &graphql.Field{
+					Type:        graphql.Int,
+					Description: "distance flown in miles",
+				},
+			},
+		},
+	)
+}
+
+// routesQuery defines a GraphQL query that accepts an origin airport code and
+// responds with the destinations that have flight data. airportType is the type
+// returned by newAirportType.
+func routesQuery(st *store.Store, airportType *graphql.Object) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(newRouteType(airportType)),
+		Description: "list destinations served from an airport",
+		Args: graphql.FieldConfigArgument{
+			"origin": locationArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			origin, _ := params.Args["origin"].(string)
+			routes, err := st.Routes(params.Context, origin)
+
+			if err == store.ErrInvalidAirportCode {
+				return nil, nil
+			}
+
+			return routes, err
+		},
+	}
+}
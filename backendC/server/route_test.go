@@ -0,0 +1,42 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pboyd/flightranker-backend/backendC/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	cases := []struct {
+		query         string
+		expectedCodes []string
+	}{
+		{
+			query:         `{routes(origin:"LAS"){destination{code},flights,carriers,lastFlight,distanceMiles}}`,
+			expectedCodes: []string{"DEN", "JFK", "LAX"},
+		},
+		{
+			query:         `{routes(origin:"VGT"){destination{code}}}`,
+			expectedCodes: []string{},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string][]store.Route
+		runTestQuery(t, c.query, &response)
+
+		actualCodes := []string{}
+		for _, route := range response["routes"] {
+			actualCodes = append(actualCodes, route.Destination.Code)
+			assert.Greater(route.Distance, 0)
+		}
+
+		assert.Subset(actualCodes, c.expectedCodes)
+		if len(c.expectedCodes) == 0 {
+			assert.Empty(actualCodes)
+		}
+	}
+}
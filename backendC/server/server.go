@@ -24,18 +24,37 @@ func Run() {
 }
 
 // Handler returns an http.Handler that responds to GraphQL queries for flight
-// stats.
+// stats, using a new store.Store that stays open for the life of the program.
 func Handler() http.Handler {
+	return NewHandler(store.New())
+}
+
+// NewHandler returns an http.Handler like Handler's that uses the store
+// instance. The caller is responsible for closing the store.
+func NewHandler(store *store.Store) http.Handler {
 	corsAllowOrigin := os.Getenv("CORS_ALLOW_ORIGIN")
 
-	store := store.New()
+	airportType := newAirportType(store)
 
 	queries := graphql.Fields{
-		"airport":              airportQuery(store),
-		"airportList":          airportListQuery(store),
+		"airport":              airportQuery(store, airportType),
+		"airportList":          airportListQuery(store, airportType),
+		"routes":               routesQuery(store, airportType),
 		"flightStatsByAirline": flightStatsByAirlineQuery(store),
 		"dailyFlightStats":     dailyFlightStatsQuery(store),
 		"monthlyFlightStats":   monthlyFlightStatsQuery(store),
+		"flightStats":          flightStatsQuery(store),
+		"airportStats":         airportStatsQuery(store),
+		"airlineRankings":      airlineRankingsQuery(store),
+		"flightNumberStats":    flightNumberStatsQuery(store),
+		"tailHistory":          tailHistoryQuery(store),
+		"connectingRoutes":     connectingRoutesQuery(store),
+		"airportsNear":         airportsNearQuery(store, airportType),
+		"carrier":              carrierQuery(store),
+		"carriers":             carriersQuery(store),
+		"bestTimesToFly":       bestTimesToFlyQuery(store),
+		"airportCongestion":    airportCongestionQuery(store),
+		"disruptions":          disruptionsQuery(store),
 	}
 
 	// register each query with prometheus
@@ -83,6 +102,34 @@ func Handler() http.Handler {
 	})
 }
 
+// isInvalidInput returns true if err was caused by an invalid argument.
+// Queries respond with null instead of an error in that case.
+func isInvalidInput(err error) bool {
+	switch err {
+	case store.ErrInvalidAirportCode,
+		store.ErrInvalidDateRange,
+		store.ErrInvalidOnTimeThreshold,
+		store.ErrInvalidState,
+		store.ErrInvalidCarrierCode,
+		store.ErrInvalidFlightNumber,
+		store.ErrInvalidTailNumber,
+		store.ErrInvalidConnectionTime,
+		store.ErrInvalidMaxStops,
+		store.ErrInvalidCoordinates,
+		store.ErrInvalidRadius,
+		store.ErrInvalidLimit,
+		store.ErrInvalidTerm,
+		store.ErrInvalidCursor,
+		store.ErrInvalidTrendMonths,
+		store.ErrInvalidHistogramEdges,
+		store.ErrInvalidReliabilityWeights,
+		store.ErrInvalidBaselineDays:
+		return true
+	}
+
+	return false
+}
+
 // instrumentResolver wraps the resolver function of a GraphQL query to record
 // performance metrics in Prometheus.
 //
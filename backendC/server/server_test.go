@@ -2,10 +2,30 @@ package server
 
 import (
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+
+	"github.com/pboyd/flightranker-backend/backendC/store"
+)
+
+// testHandler and testStore are shared by the tests so they use one database
+// connection.
+var (
+	testHandler http.Handler
+	testStore   *store.Store
 )
 
+func TestMain(m *testing.M) {
+	testStore = store.New()
+	testHandler = NewHandler(testStore)
+
+	code := m.Run()
+	testStore.Close()
+	os.Exit(code)
+}
+
 // runTestQuery runs the "query" and unmarshals the JSON body into "output".
 //
 // If the response body cannot be unmarshaled the test fails.
@@ -13,7 +33,7 @@ func runTestQuery(t *testing.T, query string, output interface{}) {
 	req := httptest.NewRequest("GET", "/?q="+query, nil)
 	res := httptest.NewRecorder()
 
-	Handler().ServeHTTP(res, req)
+	testHandler.ServeHTTP(res, req)
 
 	err := json.Unmarshal(res.Body.Bytes(), output)
 	if err != nil {
@@ -5,18 +5,195 @@ import (
 	"time"
 
 	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
 	"github.com/pboyd/flightranker-backend/backendC/store"
 )
 
 // flightStatsByAirlineRow is one row in a response from
 // flightStatsByAirlineQuery.
 type flightStatsByAirlineRow struct {
-	Airline          string    `json:"airline"`
-	Flights          int       `json:"totalFlights"`
-	OnTimePercentage float64   `json:"onTimePercentage"`
-	LastFlight       time.Time `json:"lastFlight"`
+	Code             string               `json:"code"`
+	Airline          string               `json:"airline"`
+	Flights          int                  `json:"totalFlights"`
+	OnTimePercentage float64              `json:"onTimePercentage"`
+	OnTimeLowerBound float64              `json:"onTimeLowerBound"`
+	OnTimeUpperBound float64              `json:"onTimeUpperBound"`
+	LastFlight       time.Time            `json:"lastFlight"`
+	Cancelled        int                  `json:"cancelled"`
+	Diverted         int                  `json:"diverted"`
+	Cancellations    store.Cancellations  `json:"cancellations"`
+	AverageDelay     float64              `json:"averageDelay"`
+	MedianDelay      float64              `json:"medianDelay"`
+	P90Delay         float64              `json:"p90Delay"`
+	MaxDelay         int                  `json:"maxDelay"`
+	DelayCauses      store.DelayCauses    `json:"delayCauses"`
+	DelayHistogram   store.DelayHistogram `json:"delayHistogram"`
+	SchedulePadding  float64              `json:"schedulePadding"`
+	Reliability      float64              `json:"reliability"`
+
+	Airports []store.AirportPairStats `json:"airports"`
+	Trend    *store.Trend             `json:"trend"`
+}
+
+// onTimeLowerBoundField and onTimeUpperBoundField are the GraphQL fields for
+// the confidence interval of the on-time percentage. The source must have
+// fields with matching names or json tags.
+var (
+	onTimeLowerBoundField = &graphql.Field{
+		Type:        graphql.Float,
+		Description: "lower bound of the 95% confidence interval for the on-time percentage",
+	}
+	onTimeUpperBoundField = &graphql.Field{
+		Type:        graphql.Float,
+		Description: "upper bound of the 95% confidence interval for the on-time percentage",
+	}
+)
+
+// trendType is the GraphQL definition of store.Trend.
+var trendType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name:        "trend",
+		Description: "change in the on-time percentage, in percentage points",
+		Fields: graphql.Fields{
+			"previousPeriod": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "change from the period before the latest one",
+			},
+			"previousYear": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "change from the same period a year earlier",
+			},
+			"slope": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "change per month over the trailing months",
+			},
+		},
+	},
+)
+
+// delayFields are the GraphQL fields for arrival delay statistics. The source
+// must have fields with matching names or json tags.
+func delayFields() graphql.Fields {
+	return graphql.Fields{
+		"averageDelay": &graphql.Field{
+			Type:        graphql.Float,
+			Description: "mean arrival delay in minutes, negative when flights arrive early",
+		},
+		"medianDelay": &graphql.Field{
+			Type:        graphql.Float,
+			Description: "estimated median arrival delay in minutes",
+		},
+		"p90Delay": &graphql.Field{
+			Type:        graphql.Float,
+			Description: "estimated 90th percentile arrival delay in minutes",
+		},
+		"maxDelay": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "longest arrival delay in minutes",
+		},
+		"delayCauses": &graphql.Field{
+			Type:        delayCausesType,
+			Description: "arrival delays by cause",
+		},
+		"schedulePadding": &graphql.Field{
+			Type:        graphql.Float,
+			Description: "mean minutes the scheduled flight time exceeded the actual flight time",
+		},
+		"delayHistogram": &graphql.Field{
+			Type:        delayHistogramType,
+			Description: "flights by arrival delay",
+		},
+	}
+}
+
+// delayHistogramType is the GraphQL definition of store.DelayHistogram.
+var delayHistogramType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "delayHistogram",
+		Fields: graphql.Fields{
+			"buckets": &graphql.Field{
+				Type: graphql.NewList(graphql.NewObject(
+					graphql.ObjectConfig{
+						Name: "histogramBucket",
+						Fields: graphql.Fields{
+							"min": &graphql.Field{
+								Type:        graphql.Int,
+								Description: "lowest delay in minutes in the bucket, null for the first bucket",
+							},
+							"max": &graphql.Field{
+								Type:        graphql.Int,
+								Description: "delay in minutes at the end of the bucket (excluded), null for the last bucket",
+							},
+							"flights": &graphql.Field{Type: graphql.Int},
+						},
+					},
+				)),
+				Description: "flights that arrived, from the earliest bucket to the latest",
+			},
+			"cancelled": &graphql.Field{Type: graphql.Int},
+			"diverted":  &graphql.Field{Type: graphql.Int},
+		},
+	},
+)
+
+// delayCauseType is the GraphQL definition of store.DelayCause.
+var delayCauseType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "delayCause",
+		Fields: graphql.Fields{
+			"minutes": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "total minutes of delay",
+			},
+			"flights": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "number of flights delayed",
+			},
+			"share": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "fraction of delayed flights affected (0-1)",
+			},
+		},
+	},
+)
+
+// delayCausesType is the GraphQL definition of store.DelayCauses.
+var delayCausesType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "delayCauses",
+		Fields: graphql.Fields{
+			"carrier":      &graphql.Field{Type: delayCauseType},
+			"weather":      &graphql.Field{Type: delayCauseType},
+			"nas":          &graphql.Field{Type: delayCauseType, Description: "National Aviation System"},
+			"security":     &graphql.Field{Type: delayCauseType},
+			"lateAircraft": &graphql.Field{Type: delayCauseType},
+		},
+	},
+)
+
+// withFields adds the fields from extra to fields and returns fields.
+func withFields(fields graphql.Fields, extra graphql.Fields) graphql.Fields {
+	for name, field := range extra {
+		fields[name] = field
+	}
+
+	return fields
 }
 
+// cancellationsType is the GraphQL definition of store.Cancellations.
+var cancellationsType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name:        "cancellations",
+		Description: "cancelled flights by reason",
+		Fields: graphql.Fields{
+			"carrier":  &graphql.Field{Type: graphql.Int},
+			"weather":  &graphql.Field{Type: graphql.Int},
+			"nas":      &graphql.Field{Type: graphql.Int, Description: "National Aviation System"},
+			"security": &graphql.Field{Type: graphql.Int},
+		},
+	},
+)
+
 // flightStatsByAirlineQuery defines the flightStatsByAirline GraphQL query.
 // The store instance is used when resolving the query.
 func flightStatsByAirlineQuery(st *store.Store) *graphql.Field {
@@ -24,79 +201,236 @@ func flightStatsByAirlineQuery(st *store.Store) *graphql.Field {
 		Type: graphql.NewList(
 			graphql.NewObject(graphql.ObjectConfig{
 				Name: "airlineFlightStats",
-				Fields: graphql.Fields{
+				Fields: withFields(graphql.Fields{
+					"code":             &graphql.Field{Type: graphql.String, Description: "airline code"},
 					"airline":          &graphql.Field{Type: graphql.String},
 					"totalFlights":     &graphql.Field{Type: graphql.Int},
 					"onTimePercentage": &graphql.Field{Type: graphql.Float},
+					"onTimeLowerBound": onTimeLowerBoundField,
+					"onTimeUpperBound": onTimeUpperBoundField,
 					"lastFlight":       &graphql.Field{Type: graphql.DateTime},
-				},
+					"cancelled":        &graphql.Field{Type: graphql.Int},
+					"diverted":         &graphql.Field{Type: graphql.Int},
+					"cancellations":    &graphql.Field{Type: cancellationsType},
+					"reliability":      reliabilityField,
+					"airports":         airportPairStatsField,
+					"trend": &graphql.Field{
+						Type:        trendType,
+						Description: "month over month trend",
+					},
+				}, delayFields()),
 			},
 			),
 		),
 		Args: graphql.FieldConfigArgument{
-			"origin":      airportCodeArgument,
-			"destination": airportCodeArgument,
+			"origin":      locationArgument,
+			"destination": locationArgument,
+			"from":        dateArgument,
+			"to":          dateArgument,
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+			"trendMonths":            trendMonthsArgument,
+			"delayHistogramEdges":    delayHistogramEdgesArgument,
+
+			"minFlights": &graphql.ArgumentConfig{
+				Type:        graphql.Int,
+				Description: "exclude airlines with fewer flights",
+			},
+			"rankBy": &graphql.ArgumentConfig{
+				Type:         rankByEnum,
+				DefaultValue: store.RankByOnTime,
+			},
+			"reliabilityWeights": reliabilityWeightsArgument,
 		},
 		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
 			origin, _ := params.Args["origin"].(string)
 			dest, _ := params.Args["destination"].(string)
+			minFlights, _ := params.Args["minFlights"].(int)
+			rankBy, _ := params.Args["rankBy"].(store.RankBy)
+
+			if minFlights < 0 {
+				return nil, nil
+			}
+
+			weights, ok := reliabilityWeightsArg(params)
+			if !ok {
+				return nil, nil
+			}
+
+			opts, ok := flightStatsOptsArgs(params, store.GroupByAvailable)
+			if !ok {
+				return nil, nil
+			}
 
-			stats, err := st.FlightStats(
-				params.Context,
-				origin, dest,
-				store.FlightStatsOpts{
-					TimeGroup: store.GroupByAvailable,
-				},
-			)
+			stats, err := st.FlightStats(params.Context, origin, dest, opts)
 
-			if err == store.ErrInvalidAirportCode {
+			if isInvalidInput(err) {
 				return nil, nil
 			} else if err != nil {
 				return nil, err
 			}
 
-			outStats := make([]flightStatsByAirlineRow, 0, len(stats))
+			var trends map[string]*store.Trend
+			if selectsField(params, "trend") {
+				trends, err = st.FlightTrends(params.Context, origin, dest, opts)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			ranked := make(store.Stats, 0, len(stats))
 			for _, airlineStats := range stats {
+				if airlineStats.Rows[0].Flights >= minFlights {
+					ranked = append(ranked, airlineStats)
+				}
+			}
+
+			// Rank airlines from best to worst.
+			sort.SliceStable(ranked, func(a, b int) bool {
+				return rankBy.LessWeighted(&ranked[a].Rows[0], &ranked[b].Rows[0], weights)
+			})
+
+			outStats := make([]flightStatsByAirlineRow, 0, len(ranked))
+			for _, airlineStats := range ranked {
+				row := airlineStats.Rows[0]
+				lower, upper := row.OnTimeBounds()
 				outStats = append(outStats, flightStatsByAirlineRow{
+					Code:             airlineStats.Code,
 					Airline:          airlineStats.Airline,
-					Flights:          airlineStats.Rows[0].Flights,
-					LastFlight:       airlineStats.Rows[0].End,
-					OnTimePercentage: airlineStats.Rows[0].OnTime(),
+					Flights:          row.Flights,
+					LastFlight:       row.End,
+					OnTimePercentage: row.OnTime(),
+					OnTimeLowerBound: lower,
+					OnTimeUpperBound: upper,
+					Cancelled:        row.Cancelled,
+					Diverted:         row.Diverted,
+					Cancellations:    row.Cancellations,
+					AverageDelay:     row.AverageDelay,
+					MedianDelay:      row.MedianDelay,
+					P90Delay:         row.P90Delay,
+					MaxDelay:         row.MaxDelay,
+					DelayCauses:      row.DelayCauses,
+					DelayHistogram:   row.DelayHistogram,
+					SchedulePadding:  row.SchedulePadding,
+					Reliability:      row.Reliability(weights),
+					Airports:         airlineStats.Airports,
+					Trend:            trends[airlineStats.Code],
 				})
 			}
 
-			// Rank airlines from best to worst by on-time percentage.
-			sort.Slice(outStats, func(a, b int) bool {
-				return outStats[a].OnTimePercentage > outStats[b].OnTimePercentage
-			})
-
 			return outStats, nil
 		},
 	}
 }
 
+// selectsField returns true if the query selects a field named name from the
+// result of the field being resolved, directly or through fragments.
+func selectsField(params graphql.ResolveParams, name string) bool {
+	for _, field := range params.Info.FieldASTs {
+		if selectionSetHasField(params.Info.Fragments, field.SelectionSet, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectionSetHasField returns true if set selects a field named name,
+// including the fields of any fragments it spreads.
+func selectionSetHasField(fragments map[string]ast.Definition, set *ast.SelectionSet, name string) bool {
+	if set == nil {
+		return false
+	}
+
+	for _, selection := range set.Selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Name != nil && s.Name.Value == name {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectionSetHasField(fragments, s.SelectionSet, name) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if s.Name == nil {
+				continue
+			}
+
+			fragment, ok := fragments[s.Name.Value].(*ast.FragmentDefinition)
+			if ok && selectionSetHasField(fragments, fragment.SelectionSet, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// flightStatsByDateRowType is the GraphQL definition of store.StatsRow.
+var flightStatsByDateRowType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "flightStatsByDateRow",
+		Fields: withFields(graphql.Fields{
+			"date":          &graphql.Field{Type: graphql.DateTime},
+			"flights":       &graphql.Field{Type: graphql.Int},
+			"delays":        &graphql.Field{Type: graphql.Int},
+			"cancelled":     &graphql.Field{Type: graphql.Int},
+			"diverted":      &graphql.Field{Type: graphql.Int},
+			"cancellations": &graphql.Field{Type: cancellationsType},
+			"bucket": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "day of the week (0 is Sunday) for DAY_OF_WEEK, or hour of the day (0-23) for HOUR",
+			},
+			"onTimePercentage": &graphql.Field{
+				Type:    graphql.Float,
+				Resolve: resolveOnTimePercentage,
+			},
+			"onTimeLowerBound": &graphql.Field{
+				Type:        graphql.Float,
+				Description: onTimeLowerBoundField.Description,
+				Resolve:     resolveOnTimeLowerBound,
+			},
+			"onTimeUpperBound": &graphql.Field{
+				Type:        graphql.Float,
+				Description: onTimeUpperBoundField.Description,
+				Resolve:     resolveOnTimeUpperBound,
+			},
+		}, delayFields()),
+	},
+)
+
+// airportPairStatsField is the GraphQL definition of the per-airport breakdown
+// in store.AirlineStats.
+var airportPairStatsField = &graphql.Field{
+	Type: graphql.NewList(graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "airportPairStats",
+			Fields: graphql.Fields{
+				"origin":      &graphql.Field{Type: graphql.String},
+				"destination": &graphql.Field{Type: graphql.String},
+				"rows":        &graphql.Field{Type: graphql.NewList(flightStatsByDateRowType)},
+			},
+		},
+	)),
+	Description: "stats for each pair of airports when the origin or destination is a metro area",
+}
+
 // flightStatsByDateType is the GraphQL definition of the return value from
-// dailyFlightStatsQuery and monthylyFlightStatsQuery.
+// flightStatsQuery, airportStatsQuery, dailyFlightStatsQuery and
+// monthylyFlightStatsQuery.
 var flightStatsByDateType = graphql.NewList(
 	graphql.NewObject(graphql.ObjectConfig{
 		Name: "flightStatsByDate",
 		Fields: graphql.Fields{
-			"airline": &graphql.Field{Type: graphql.String},
-			"rows": &graphql.Field{Type: graphql.NewList(graphql.NewObject(
-				graphql.ObjectConfig{
-					Name: "flightStatsByDateRow",
-					Fields: graphql.Fields{
-						"date":    &graphql.Field{Type: graphql.DateTime},
-						"flights": &graphql.Field{Type: graphql.Int},
-						"delays":  &graphql.Field{Type: graphql.Int},
-						"onTimePercentage": &graphql.Field{
-							Type:    graphql.Float,
-							Resolve: resolveOnTimePercentage,
-						},
-					},
-				},
-			),
-			)},
+			"code":     &graphql.Field{Type: graphql.String, Description: "airline code"},
+			"airline":  &graphql.Field{Type: graphql.String},
+			"rows":     &graphql.Field{Type: graphql.NewList(flightStatsByDateRowType)},
+			"airports": airportPairStatsField,
+			"trend": &graphql.Field{
+				Type:        trendType,
+				Description: "trend across the rows, null for AVAILABLE and cyclic groupings",
+			},
 		},
 	},
 	),
@@ -113,6 +447,30 @@ func resolveOnTimePercentage(params graphql.ResolveParams) (interface{}, error)
 	return row.OnTime(), nil
 }
 
+// resolveOnTimeLowerBound is a graphql.Resolver that returns the lower bound
+// from the OnTimeBounds function of a source.StatsRow.
+func resolveOnTimeLowerBound(params graphql.ResolveParams) (interface{}, error) {
+	row, ok := params.Source.(store.StatsRow)
+	if !ok {
+		return 0, nil
+	}
+
+	lower, _ := row.OnTimeBounds()
+	return lower, nil
+}
+
+// resolveOnTimeUpperBound is a graphql.Resolver that returns the upper bound
+// from the OnTimeBounds function of a source.StatsRow.
+func resolveOnTimeUpperBound(params graphql.ResolveParams) (interface{}, error) {
+	row, ok := params.Source.(store.StatsRow)
+	if !ok {
+		return 0, nil
+	}
+
+	_, upper := row.OnTimeBounds()
+	return upper, nil
+}
+
 // dailyFlightStatsQuery defines the dailyFlightStats GraphQL query, which
 // returns flight stats grouped by day.
 // The store instance is used when resolving the query.
@@ -120,22 +478,27 @@ func dailyFlightStatsQuery(st *store.Store) *graphql.Field {
 	return &graphql.Field{
 		Type: flightStatsByDateType,
 		Args: graphql.FieldConfigArgument{
-			"origin":      airportCodeArgument,
-			"destination": airportCodeArgument,
+			"origin":      locationArgument,
+			"destination": locationArgument,
+			"from":        dateArgument,
+			"to":          dateArgument,
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+			"trendMonths":            trendMonthsArgument,
+			"delayHistogramEdges":    delayHistogramEdgesArgument,
 		},
 		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
 			origin, _ := params.Args["origin"].(string)
 			dest, _ := params.Args["destination"].(string)
 
-			stats, err := st.FlightStats(
-				params.Context,
-				origin, dest,
-				store.FlightStatsOpts{
-					TimeGroup: store.GroupByDay,
-				},
-			)
+			opts, ok := flightStatsOptsArgs(params, store.GroupByDay)
+			if !ok {
+				return nil, nil
+			}
+
+			stats, err := st.FlightStats(params.Context, origin, dest, opts)
 
-			if err == store.ErrInvalidAirportCode {
+			if isInvalidInput(err) {
 				return nil, nil
 			} else if err != nil {
 				return nil, err
@@ -153,22 +516,27 @@ func monthlyFlightStatsQuery(st *store.Store) *graphql.Field {
 	return &graphql.Field{
 		Type: flightStatsByDateType,
 		Args: graphql.FieldConfigArgument{
-			"origin":      airportCodeArgument,
-			"destination": airportCodeArgument,
+			"origin":      locationArgument,
+			"destination": locationArgument,
+			"from":        dateArgument,
+			"to":          dateArgument,
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+			"trendMonths":            trendMonthsArgument,
+			"delayHistogramEdges":    delayHistogramEdgesArgument,
 		},
 		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
 			origin, _ := params.Args["origin"].(string)
 			dest, _ := params.Args["destination"].(string)
 
-			stats, err := st.FlightStats(
-				params.Context,
-				origin, dest,
-				store.FlightStatsOpts{
-					TimeGroup: store.GroupByMonth,
-				},
-			)
+			opts, ok := flightStatsOptsArgs(params, store.GroupByMonth)
+			if !ok {
+				return nil, nil
+			}
+
+			stats, err := st.FlightStats(params.Context, origin, dest, opts)
 
-			if err == store.ErrInvalidAirportCode {
+			if isInvalidInput(err) {
 				return nil, nil
 			} else if err != nil {
 				return nil, err
@@ -178,3 +546,208 @@ func monthlyFlightStatsQuery(st *store.Store) *graphql.Field {
 		},
 	}
 }
+
+// timeGroupEnum is the GraphQL definition of store.TimeGroup.
+var timeGroupEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TimeGroup",
+	Values: graphql.EnumValueConfigMap{
+		"AVAILABLE": &graphql.EnumValueConfig{
+			Value:       store.GroupByAvailable,
+			Description: "all available data in one row",
+		},
+		"DAY":     &graphql.EnumValueConfig{Value: store.GroupByDay},
+		"WEEK":    &graphql.EnumValueConfig{Value: store.GroupByWeek},
+		"MONTH":   &graphql.EnumValueConfig{Value: store.GroupByMonth},
+		"QUARTER": &graphql.EnumValueConfig{Value: store.GroupByQuarter},
+		"YEAR":    &graphql.EnumValueConfig{Value: store.GroupByYear},
+		"DAY_OF_WEEK": &graphql.EnumValueConfig{
+			Value:       store.GroupByDayOfWeek,
+			Description: "one row for each day of the week",
+		},
+		"HOUR": &graphql.EnumValueConfig{
+			Value:       store.GroupByHour,
+			Description: "one row for each hour of scheduled departure",
+		},
+	},
+})
+
+// timeGroupArgument is the GraphQL definition for an argument that accepts a
+// TimeGroup.
+var timeGroupArgument = &graphql.ArgumentConfig{
+	Type:         timeGroupEnum,
+	DefaultValue: store.GroupByAvailable,
+	Description:  "time period to include in each row",
+}
+
+// flightStatsQuery defines the flightStats GraphQL query, which returns flight
+// stats grouped by the time period in the groupBy argument.
+// The store instance is used when resolving the query.
+func flightStatsQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type: flightStatsByDateType,
+		Args: graphql.FieldConfigArgument{
+			"origin":      locationArgument,
+			"destination": locationArgument,
+			"from":        dateArgument,
+			"to":          dateArgument,
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+			"trendMonths":            trendMonthsArgument,
+			"delayHistogramEdges":    delayHistogramEdgesArgument,
+			"groupBy":                timeGroupArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			origin, _ := params.Args["origin"].(string)
+			dest, _ := params.Args["destination"].(string)
+			group, _ := params.Args["groupBy"].(store.TimeGroup)
+
+			opts, ok := flightStatsOptsArgs(params, group)
+			if !ok {
+				return nil, nil
+			}
+
+			stats, err := st.FlightStats(params.Context, origin, dest, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return stats, nil
+		},
+	}
+}
+
+// directionEnum is the GraphQL definition of store.Direction.
+var directionEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Direction",
+	Values: graphql.EnumValueConfigMap{
+		"DEPARTURES": &graphql.EnumValueConfig{Value: store.Departures},
+		"ARRIVALS":   &graphql.EnumValueConfig{Value: store.Arrivals},
+	},
+})
+
+// airportStatsQuery defines the airportStats GraphQL query, which returns
+// flight stats for every route departing from or arriving at an airport.
+// The store instance is used when resolving the query.
+func airportStatsQuery(st *store.Store) *graphql.Field {
+	return &graphql.Field{
+		Type: flightStatsByDateType,
+		Args: graphql.FieldConfigArgument{
+			"code": airportCodeArgument,
+			"direction": &graphql.ArgumentConfig{
+				Type:         directionEnum,
+				DefaultValue: store.Departures,
+				Description:  "DEPARTURES for flights leaving the airport or ARRIVALS for flights arriving",
+			},
+			"from": dateArgument,
+			"to":   dateArgument,
+
+			"onTimeThresholdMinutes": onTimeThresholdArgument,
+			"trendMonths":            trendMonthsArgument,
+			"delayHistogramEdges":    delayHistogramEdgesArgument,
+			"groupBy":                timeGroupArgument,
+		},
+		Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+			code, _ := params.Args["code"].(string)
+			direction, _ := params.Args["direction"].(store.Direction)
+			group, _ := params.Args["groupBy"].(store.TimeGroup)
+
+			opts, ok := flightStatsOptsArgs(params, group)
+			if !ok {
+				return nil, nil
+			}
+
+			stats, err := st.AirportStats(params.Context, code, direction, opts)
+
+			if isInvalidInput(err) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return stats, nil
+		},
+	}
+}
+
+// onTimeThresholdArgument is the GraphQL definition for an argument that
+// accepts the number of minutes a flight can be late and still be on time.
+var onTimeThresholdArgument = &graphql.ArgumentConfig{
+	Type:         graphql.Int,
+	DefaultValue: store.DefaultOnTimeThreshold,
+	Description:  "minutes after the scheduled arrival when a flight is considered late",
+}
+
+// trendMonthsArgument is the GraphQL definition for an argument that accepts
+// the number of trailing months in a trend's slope.
+var trendMonthsArgument = &graphql.ArgumentConfig{
+	Type:         graphql.Int,
+	DefaultValue: store.DefaultTrendMonths,
+	Description:  "number of trailing months used for the trend's slope",
+}
+
+// delayHistogramEdgesArgument is the GraphQL definition for an argument that
+// accepts the bucket edges of a delay histogram.
+var delayHistogramEdgesArgument = &graphql.ArgumentConfig{
+	Type:        graphql.NewList(graphql.Int),
+	Description: "ascending delay histogram bucket edges in minutes, each a multiple of 5 (default [0, 15, 30, 60, 120])",
+}
+
+// dateArgument is the GraphQL definition for an argument that accepts a date.
+var dateArgument = &graphql.ArgumentConfig{
+	Type:        graphql.String,
+	Description: "date in YYYY-MM-DD format (e.g. 2019-01-31)",
+}
+
+// flightStatsOptsArgs reads the arguments that are common to the flight stats
+// queries into a FlightStatsOpts with group as the TimeGroup.
+//
+// ok is false if any argument is invalid.
+func flightStatsOptsArgs(params graphql.ResolveParams, group store.TimeGroup) (opts store.FlightStatsOpts, ok bool) {
+	opts.TimeGroup = group
+	opts.OnTimeThreshold, _ = params.Args["onTimeThresholdMinutes"].(int)
+	opts.TrendMonths, _ = params.Args["trendMonths"].(int)
+
+	edges, _ := params.Args["delayHistogramEdges"].([]interface{})
+	for _, edge := range edges {
+		minutes, isInt := edge.(int)
+		if !isInt {
+			return
+		}
+		opts.HistogramEdges = append(opts.HistogramEdges, minutes)
+	}
+
+	opts.From, opts.To, ok = dateRangeArgs(params)
+	return
+}
+
+// dateRangeArgs reads the optional "from" and "to" arguments. A missing
+// argument is returned as a zero time.
+//
+// ok is false if either argument is not a valid date.
+func dateRangeArgs(params graphql.ResolveParams) (from, to time.Time, ok bool) {
+	from, ok = dateArg(params, "from")
+	if !ok {
+		return
+	}
+
+	to, ok = dateArg(params, "to")
+	return
+}
+
+// dateArg reads a date argument in YYYY-MM-DD format.
+func dateArg(params graphql.ResolveParams, key string) (time.Time, bool) {
+	value, _ := params.Args[key].(string)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
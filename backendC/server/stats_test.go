@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"sort"
 	"testing"
 
 	"github.com/pboyd/flightranker-backend/backendC/store"
@@ -9,18 +11,109 @@ import (
 
 func TestFlightStatsByAirline(t *testing.T) {
 	cases := []struct {
-		query    string
-		expected []flightStatsByAirlineRow
+		query        string
+		origin, dest string
+		threshold    int
+		airlines     []string
 	}{
 		{
-			query: `{flightStatsByAirline(origin:"LAS",destination:"JFK"){airline}}`,
-			expected: []flightStatsByAirlineRow{
-				{Airline: "JetBlue Airways"},
-				{Airline: "American Airlines Inc."},
-				{Airline: "Delta Air Lines Inc."},
-				{Airline: "Alaska Airlines Inc."},
+			query:  `{flightStatsByAirline(origin:"LAS",destination:"JFK"){airline,onTimePercentage}}`,
+			origin: "LAS",
+			dest:   "JFK",
+			airlines: []string{
+				"Alaska Airlines Inc.",
+				"American Airlines Inc.",
+				"Delta Air Lines Inc.",
+				"JetBlue Airways",
 			},
 		},
+		{
+			query:     `{flightStatsByAirline(origin:"LAS",destination:"JFK",onTimeThresholdMinutes:60){airline,onTimePercentage}}`,
+			origin:    "LAS",
+			dest:      "JFK",
+			threshold: 60,
+			airlines: []string{
+				"Alaska Airlines Inc.",
+				"American Airlines Inc.",
+				"Delta Air Lines Inc.",
+				"JetBlue Airways",
+			},
+		},
+		{
+			query:    `{flightStatsByAirline(origin:"LAS",destination:"VGT"){airline}}`,
+			origin:   "LAS",
+			dest:     "VGT",
+			airlines: []string{},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string][]flightStatsByAirlineRow
+		runTestQuery(t, c.query, &response)
+
+		rows := response["flightStatsByAirline"]
+		if !assert.NotNil(rows, c.query) {
+			continue
+		}
+
+		airlines := make([]string, len(rows))
+		for i, row := range rows {
+			airlines[i] = row.Airline
+		}
+
+		assert.ElementsMatch(c.airlines, airlines, c.query)
+		assert.Equal(rankedAirlines(t, c.origin, c.dest, c.threshold), airlines, c.query)
+	}
+}
+
+// rankedAirlines returns the names of the airlines flying from origin to
+// dest with the best on-time percentage first. The store's delay counts are
+// checked against the flights table by its own tests.
+func rankedAirlines(t *testing.T, origin, dest string, threshold int) []string {
+	stats, err := testStore.FlightStats(context.Background(), origin, dest, store.FlightStatsOpts{OnTimeThreshold: threshold})
+	if err != nil {
+		t.Fatalf("unable to get flight stats: %v", err)
+	}
+
+	sort.SliceStable(stats, func(a, b int) bool {
+		return store.RankByOnTime.Less(&stats[a].Rows[0], &stats[b].Rows[0])
+	})
+
+	airlines := make([]string, len(stats))
+	for i, airline := range stats {
+		airlines[i] = airline.Airline
+	}
+
+	return airlines
+}
+
+func TestFlightStatsByAirlineCancellations(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK"){airline,cancelled,diverted,cancellations{carrier,weather,nas,security}}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["flightStatsByAirline"])
+
+	for _, row := range response["flightStatsByAirline"] {
+		c := row.Cancellations
+		assert.Equal(row.Cancelled, c.Carrier+c.Weather+c.NAS+c.Security)
+	}
+}
+
+func TestFlightStatsOnTimeThreshold(t *testing.T) {
+	cases := []struct {
+		query       string
+		expectEmpty bool
+	}{
+		{
+			query: `{flightStatsByAirline(origin:"LAS",destination:"JFK",onTimeThresholdMinutes:30){airline,onTimePercentage}}`,
+		},
+		{
+			query:       `{flightStatsByAirline(origin:"LAS",destination:"JFK",onTimeThresholdMinutes:-1){airline,onTimePercentage}}`,
+			expectEmpty: true,
+		},
 	}
 
 	assert := assert.New(t)
@@ -28,7 +121,80 @@ func TestFlightStatsByAirline(t *testing.T) {
 	for _, c := range cases {
 		var response map[string][]flightStatsByAirlineRow
 		runTestQuery(t, c.query, &response)
-		assert.Equal(c.expected, response["flightStatsByAirline"])
+
+		if c.expectEmpty {
+			assert.Empty(response["flightStatsByAirline"])
+		} else {
+			assert.NotEmpty(response["flightStatsByAirline"])
+		}
+	}
+}
+
+func TestFlightStatsByAirlineRankBy(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",rankBy:ON_TIME_LOWER_BOUND,minFlights:100){airline,totalFlights,onTimePercentage,onTimeLowerBound,onTimeUpperBound}}`, &response)
+
+	assert := assert.New(t)
+	rows := response["flightStatsByAirline"]
+	assert.NotEmpty(rows)
+
+	for i, row := range rows {
+		assert.GreaterOrEqual(row.Flights, 100)
+		assert.True(row.OnTimeLowerBound <= row.OnTimePercentage)
+		assert.True(row.OnTimeUpperBound >= row.OnTimePercentage)
+
+		if i > 0 {
+			assert.True(rows[i-1].OnTimeLowerBound >= row.OnTimeLowerBound)
+		}
+	}
+}
+
+func TestFlightStatsByAirlineReliability(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",rankBy:RELIABILITY,reliabilityWeights:{onTime:1,cancellation:2}){airline,reliability}}`, &response)
+
+	assert := assert.New(t)
+	rows := response["flightStatsByAirline"]
+	assert.NotEmpty(rows)
+
+	for i, row := range rows {
+		assert.True(row.Reliability >= 0 && row.Reliability <= 100)
+
+		if i > 0 {
+			assert.True(rows[i-1].Reliability >= row.Reliability)
+		}
+	}
+
+	response = nil
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",reliabilityWeights:{onTime:-1}){airline}}`, &response)
+	assert.Empty(response["flightStatsByAirline"])
+}
+
+func TestFlightStatsByAirlineDelays(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK"){airline,averageDelay,medianDelay,p90Delay,maxDelay}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["flightStatsByAirline"])
+
+	for _, row := range response["flightStatsByAirline"] {
+		assert.LessOrEqual(row.MedianDelay, row.P90Delay)
+		assert.LessOrEqual(row.AverageDelay, float64(row.MaxDelay))
+	}
+}
+
+func TestFlightStatsByAirlineDelayCauses(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK"){airline,delayCauses{carrier{minutes,flights,share},weather{minutes,flights,share}}}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["flightStatsByAirline"])
+
+	for _, row := range response["flightStatsByAirline"] {
+		for _, cause := range []store.DelayCause{row.DelayCauses.Carrier, row.DelayCauses.Weather} {
+			assert.GreaterOrEqual(cause.Minutes, 0)
+			assert.True(cause.Share >= 0 && cause.Share <= 1)
+		}
 	}
 }
 
@@ -62,6 +228,113 @@ func TestDailyFlightStats(t *testing.T) {
 	}
 }
 
+func TestFlightStatsDateRange(t *testing.T) {
+	cases := []struct {
+		query            string
+		expectedAirlines []string
+	}{
+		{
+			query: `{dailyFlightStats(origin:"LAS",destination:"JFK",from:"2019-03-01",to:"2019-03-31"){airline,rows{date}}}`,
+			expectedAirlines: []string{
+				"Alaska Airlines Inc.",
+				"American Airlines Inc.",
+				"Delta Air Lines Inc.",
+				"JetBlue Airways",
+			},
+		},
+		{
+			query:            `{dailyFlightStats(origin:"LAS",destination:"JFK",from:"2019-03-31",to:"2019-03-01"){airline,rows{date}}}`,
+			expectedAirlines: []string{},
+		},
+		{
+			query:            `{dailyFlightStats(origin:"LAS",destination:"JFK",from:"March"){airline,rows{date}}}`,
+			expectedAirlines: []string{},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string][]store.AirlineStats
+		runTestQuery(t, c.query, &response)
+
+		actualAirlines := []string{}
+		for _, row := range response["dailyFlightStats"] {
+			actualAirlines = append(actualAirlines, row.Airline)
+		}
+		assert.Equal(c.expectedAirlines, actualAirlines)
+	}
+}
+
+func TestFlightStats(t *testing.T) {
+	cases := []struct {
+		query            string
+		expectedAirlines []string
+	}{
+		{
+			query: `{flightStats(origin:"LAS",destination:"JFK",groupBy:WEEK){airline,rows{date,onTimePercentage}}}`,
+			expectedAirlines: []string{
+				"Alaska Airlines Inc.",
+				"American Airlines Inc.",
+				"Delta Air Lines Inc.",
+				"JetBlue Airways",
+			},
+		},
+		{
+			query: `{flightStats(origin:"LAS",destination:"JFK",groupBy:DAY_OF_WEEK){airline,rows{bucket,onTimePercentage}}}`,
+			expectedAirlines: []string{
+				"Alaska Airlines Inc.",
+				"American Airlines Inc.",
+				"Delta Air Lines Inc.",
+				"JetBlue Airways",
+			},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string][]store.AirlineStats
+		runTestQuery(t, c.query, &response)
+
+		actualAirlines := []string{}
+		for _, row := range response["flightStats"] {
+			actualAirlines = append(actualAirlines, row.Airline)
+		}
+		assert.Equal(c.expectedAirlines, actualAirlines)
+	}
+}
+
+func TestAirportStats(t *testing.T) {
+	cases := []struct {
+		query            string
+		expectedAirlines []string
+	}{
+		{
+			query: `{airportStats(code:"JFK",direction:ARRIVALS,groupBy:MONTH){airline,rows{date,onTimePercentage}}}`,
+			expectedAirlines: []string{
+				"Alaska Airlines Inc.",
+				"American Airlines Inc.",
+				"Delta Air Lines Inc.",
+				"JetBlue Airways",
+			},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var response map[string][]store.AirlineStats
+		runTestQuery(t, c.query, &response)
+
+		actualAirlines := []string{}
+		for _, row := range response["airportStats"] {
+			actualAirlines = append(actualAirlines, row.Airline)
+		}
+		assert.Subset(actualAirlines, c.expectedAirlines)
+	}
+}
+
 func TestMonthlyFlightStats(t *testing.T) {
 	cases := []struct {
 		query            string
@@ -91,3 +364,83 @@ func TestMonthlyFlightStats(t *testing.T) {
 		assert.Equal(c.expectedAirlines, actualAirlines)
 	}
 }
+
+func TestFlightStatsByAirlineMetro(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"NYC",destination:"CHI"){airline,totalFlights,airports{origin,destination,rows{flights}}}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["flightStatsByAirline"])
+
+	for _, row := range response["flightStatsByAirline"] {
+		total := 0
+		for _, pair := range row.Airports {
+			total += pair.Rows[0].Flights
+		}
+
+		assert.Equal(row.Flights, total, row.Airline)
+	}
+}
+
+func TestFlightStatsByAirlineTrend(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",trendMonths:3){code,trend{previousPeriod,previousYear,slope}}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["flightStatsByAirline"])
+
+	for _, row := range response["flightStatsByAirline"] {
+		assert.NotNil(row.Trend, row.Code)
+	}
+
+	response = nil
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",trendMonths:-1){code,trend{slope}}}`, &response)
+	assert.Empty(response["flightStatsByAirline"])
+}
+
+func TestFlightStatsByAirlineTrendFragments(t *testing.T) {
+	queries := []string{
+		`{flightStatsByAirline(origin:"LAS",destination:"JFK"){code,...trend}} fragment trend on airlineFlightStats{trend{slope}}`,
+		`{flightStatsByAirline(origin:"LAS",destination:"JFK"){code,... on airlineFlightStats{trend{slope}}}}`,
+	}
+
+	assert := assert.New(t)
+
+	for _, query := range queries {
+		var response map[string][]flightStatsByAirlineRow
+		runTestQuery(t, query, &response)
+		assert.NotEmpty(response["flightStatsByAirline"], query)
+
+		for _, row := range response["flightStatsByAirline"] {
+			assert.NotNil(row.Trend, query)
+		}
+	}
+}
+
+func TestFlightStatsDelayHistogram(t *testing.T) {
+	var response map[string][]flightStatsByAirlineRow
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",delayHistogramEdges:[0,60]){totalFlights,delayHistogram{buckets{min,max,flights},cancelled,diverted}}}`, &response)
+
+	assert := assert.New(t)
+	assert.NotEmpty(response["flightStatsByAirline"])
+
+	for _, row := range response["flightStatsByAirline"] {
+		hist := row.DelayHistogram
+		if !assert.Len(hist.Buckets, 3) {
+			continue
+		}
+
+		assert.Nil(hist.Buckets[0].Min)
+		assert.Equal(60, *hist.Buckets[2].Min)
+
+		total := hist.Cancelled + hist.Diverted
+		for _, b := range hist.Buckets {
+			total += b.Flights
+		}
+		assert.Equal(row.Flights, total)
+	}
+
+	response = nil
+	runTestQuery(t, `{flightStatsByAirline(origin:"LAS",destination:"JFK",delayHistogramEdges:[0,7]){totalFlights}}`, &response)
+	assert.Empty(response["flightStatsByAirline"])
+}
@@ -0,0 +1,254 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// AircraftLeg is one flight by an aircraft.
+type AircraftLeg struct {
+	// Leg is the position of the flight in the aircraft's day, starting
+	// at 1.
+	Leg int `json:"leg"`
+
+	// Carrier is the airline's code.
+	Carrier string `json:"carrier"`
+
+	// Airline is the airline's name.
+	Airline string `json:"airline"`
+
+	// FlightNumber is the flight number without the carrier code.
+	FlightNumber string `json:"flightNumber"`
+
+	// Origin is the IATA code of the departure airport.
+	Origin string `json:"origin"`
+
+	// Destination is the IATA code of the arrival airport.
+	Destination string `json:"destination"`
+
+	// ScheduledDeparture and ScheduledArrival are the scheduled local
+	// times in hh:mm format.
+	ScheduledDeparture string `json:"scheduledDeparture"`
+	ScheduledArrival   string `json:"scheduledArrival"`
+
+	// Departure and Arrival are the actual local times in hh:mm format.
+	// They're empty when the flight was cancelled, and Arrival is empty
+	// when the flight was diverted.
+	Departure string `json:"departure"`
+	Arrival   string `json:"arrival"`
+
+	// DepartureDelay and ArrivalDelay are the delays in minutes. Early
+	// flights are negative.
+	DepartureDelay int `json:"departureDelay"`
+	ArrivalDelay   int `json:"arrivalDelay"`
+
+	Cancelled bool `json:"cancelled"`
+	Diverted  bool `json:"diverted"`
+
+	// LateAircraftDelay is the number of minutes the flight was delayed
+	// because the aircraft arrived late from its previous flight.
+	LateAircraftDelay int `json:"lateAircraftDelay"`
+
+	// InboundLeg is the Leg that brought the aircraft to Origin, or 0 if
+	// this is the aircraft's first flight from Origin that day.
+	// LateAircraftDelay is caused by the inbound leg.
+	InboundLeg int `json:"inboundLeg"`
+}
+
+// TailHistory returns the flights an aircraft flew on a day, ordered by
+// scheduled departure time.
+//
+// tailNumber is the aircraft's registration (e.g. "N123AA"). If it is invalid
+// ErrInvalidTailNumber is returned. If the aircraft didn't fly that day the
+// result is empty.
+func (s *Store) TailHistory(ctx context.Context, tailNumber string, date time.Time) ([]AircraftLeg, error) {
+	tailNumber = strings.ToUpper(tailNumber)
+	if !isTailNumber(tailNumber) {
+		return nil, ErrInvalidTailNumber
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			carrier,
+			carriers.name,
+			flight_number,
+			origin,
+			destination,
+			scheduled_departure_time,
+			scheduled_arrival_time,
+			departure_time,
+			arrival_time,
+			departure_delay,
+			arrival_delay,
+			cancelled,
+			diverted,
+			late_aircraft_delay
+		FROM
+			flights
+			INNER JOIN carriers ON carrier=carriers.code
+		WHERE tail_number=? AND date=?
+		ORDER BY scheduled_departure_time, id`,
+		tailNumber, date.Format(dateFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := []AircraftLeg{}
+	for rows.Next() {
+		var leg AircraftLeg
+
+		err := rows.Scan(&leg.Carrier, &leg.Airline, &leg.FlightNumber,
+			&leg.Origin, &leg.Destination,
+			&leg.ScheduledDeparture, &leg.ScheduledArrival,
+			&leg.Departure, &leg.Arrival,
+			&leg.DepartureDelay, &leg.ArrivalDelay,
+			&leg.Cancelled, &leg.Diverted,
+			&leg.LateAircraftDelay)
+		if err != nil {
+			return nil, err
+		}
+
+		leg.Leg = len(legs) + 1
+		leg.ScheduledDeparture = formatClock(leg.ScheduledDeparture)
+		leg.ScheduledArrival = formatClock(leg.ScheduledArrival)
+		leg.Departure = formatClock(leg.Departure)
+		leg.Arrival = formatClock(leg.Arrival)
+
+		// Cancelled and diverted flights are loaded with zero times.
+		if leg.Cancelled {
+			leg.Departure = ""
+		}
+		if leg.Cancelled || leg.Diverted {
+			leg.Arrival = ""
+		}
+
+		legs = append(legs, leg)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	setInboundLegs(legs)
+
+	return legs, nil
+}
+
+// setInboundLegs sets InboundLeg for each leg in a day that's ordered by
+// scheduled departure. The inbound leg is the most recent flight that landed
+// at the leg's origin.
+func setInboundLegs(legs []AircraftLeg) {
+	for i := range legs {
+		for j := i - 1; j >= 0; j-- {
+			prev := &legs[j]
+			if prev.Cancelled || prev.Diverted {
+				continue
+			}
+
+			if prev.Destination == legs[i].Origin {
+				legs[i].InboundLeg = prev.Leg
+			}
+			break
+		}
+	}
+}
+
+// InboundDelay contains the late aircraft delays of a flight that were caused
+// by aircraft arriving from one airport.
+type InboundDelay struct {
+	// Origin is the IATA code of the airport the aircraft arrived from.
+	Origin string `json:"origin"`
+
+	// Flights is the number of flights delayed by a late aircraft from
+	// Origin.
+	Flights int `json:"flights"`
+
+	// Minutes is the total late aircraft delay of those flights.
+	Minutes int `json:"minutes"`
+
+	// AverageInboundDelay is the mean arrival delay in minutes of the
+	// inbound flights.
+	AverageInboundDelay float64 `json:"averageInboundDelay"`
+}
+
+// inboundDelays attributes the late aircraft delays of the flights matching
+// where to the flight that brought the aircraft. where and args are SQL
+// conditions and placeholder values on the flights table, which is aliased as
+// "f".
+//
+// Only inbound flights on the same day are found, so delays caused by an
+// aircraft arriving overnight aren't included. The results are ordered from
+// the most minutes to the least.
+func (s *Store) inboundDelays(ctx context.Context, where []string, args []interface{}) ([]InboundDelay, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			inbound.origin,
+			COUNT(*),
+			SUM(f.late_aircraft_delay) AS minutes,
+			AVG(inbound.arrival_delay)
+		FROM
+			flights f
+			INNER JOIN flights inbound ON inbound.id=(
+				SELECT prev.id
+				FROM flights prev
+				WHERE
+					prev.tail_number=f.tail_number
+					AND prev.date=f.date
+					AND prev.destination=f.origin
+					AND prev.cancelled=0
+					AND prev.diverted=0
+					AND prev.scheduled_departure_time<f.scheduled_departure_time
+				ORDER BY prev.scheduled_departure_time DESC
+				LIMIT 1
+			)
+		WHERE
+			f.late_aircraft_delay>0
+			AND f.tail_number<>''
+			AND `+strings.Join(where, " AND ")+`
+		GROUP BY inbound.origin
+		ORDER BY minutes DESC, inbound.origin`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delays := []InboundDelay{}
+	for rows.Next() {
+		var d InboundDelay
+		err := rows.Scan(&d.Origin, &d.Flights, &d.Minutes, &d.AverageInboundDelay)
+		if err != nil {
+			return nil, err
+		}
+
+		delays = append(delays, d)
+	}
+
+	return delays, rows.Err()
+}
+
+// formatClock converts a MySQL TIME value ("hh:mm:ss") to "hh:mm".
+func formatClock(t string) string {
+	if len(t) < 5 {
+		return t
+	}
+
+	return t[:5]
+}
+
+func isTailNumber(tailNumber string) bool {
+	if len(tailNumber) < 2 || len(tailNumber) > 6 {
+		return false
+	}
+
+	for _, r := range tailNumber {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailHistory(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	var (
+		tailNumber string
+		date       time.Time
+		count      int
+	)
+	err := store.db.QueryRow(`
+		SELECT tail_number, date, COUNT(*) AS legs
+		FROM flights
+		WHERE origin='DEN' AND tail_number<>''
+		GROUP BY tail_number, date
+		ORDER BY legs DESC
+		LIMIT 1`).Scan(&tailNumber, &date, &count)
+	if !assert.NoError(err) {
+		return
+	}
+
+	legs, err := store.TailHistory(context.Background(), tailNumber, date)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Len(legs, count)
+	for i, leg := range legs {
+		assert.Equal(i+1, leg.Leg)
+		assert.Less(leg.InboundLeg, leg.Leg)
+		assert.Len(leg.ScheduledDeparture, 5)
+
+		if i > 0 {
+			assert.LessOrEqual(legs[i-1].ScheduledDeparture, leg.ScheduledDeparture)
+		}
+
+		if leg.InboundLeg > 0 {
+			assert.Equal(leg.Origin, legs[leg.InboundLeg-1].Destination)
+		}
+	}
+
+	legs, err = store.TailHistory(context.Background(), tailNumber, date.AddDate(-20, 0, 0))
+	assert.NoError(err)
+	assert.Empty(legs)
+
+	_, err = store.TailHistory(context.Background(), "N1$", date)
+	assert.Equal(ErrInvalidTailNumber, err)
+}
+
+func TestSetInboundLegs(t *testing.T) {
+	legs := []AircraftLeg{
+		{Leg: 1, Origin: "DEN", Destination: "LAS"},
+		{Leg: 2, Origin: "LAS", Destination: "LAX"},
+		{Leg: 3, Origin: "LAX", Destination: "SFO", Cancelled: true},
+		{Leg: 4, Origin: "LAX", Destination: "PHX"},
+		{Leg: 5, Origin: "SFO", Destination: "DEN"},
+	}
+
+	setInboundLegs(legs)
+
+	actual := make([]int, 0, len(legs))
+	for _, leg := range legs {
+		actual = append(actual, leg.InboundLeg)
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 2, 0}, actual)
+}
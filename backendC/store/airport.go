@@ -51,49 +51,71 @@ func (s *Store) Airport(ctx context.Context, code string) (*Airport, error) {
 	return &a, nil
 }
 
-// AirportSearch finds airports with a name, city or code that contains the
-// term.
+// SearchOpts contains options for AirportSearch.
+type SearchOpts struct {
+	// First is the maximum number of airports to return. Zero means no
+	// limit.
+	First int
+
+	// After is the code of the last airport on the previous page of
+	// results. The results start with the airport that follows it. Empty
+	// means the first page.
+	After string
+}
+
+// AirportSearch finds airports with a name, city or code that matches the
+// term. It searches an in-memory index of the active airports, so it doesn't
+// use the database.
+//
+// The results are ranked by how well they match: an exact code match first,
+// then airports with a code, city or name that starts with the term, then
+// airports that contain the term. After those come airports where each word
+// in the term starts a word in the city or name, in any order, and finally
+// the same with a few typos allowed (e.g. "chigaco"). Ties are broken by the
+// number of flights, busiest first.
 //
 // If nothing matches an empty list is returned.
 //
 // The term must contain only Latin1 letters, Latin1 numbers, dashes ("-") and
-// spaces. If it contains any other character ErrInvalidTerm is returned.
-func (s *Store) AirportSearch(ctx context.Context, term string) ([]*Airport, error) {
+// spaces. If it contains any other character ErrInvalidTerm is returned. If
+// opts.First is negative ErrInvalidLimit is returned, and if opts.After isn't
+// in the results ErrInvalidCursor is returned.
+func (s *Store) AirportSearch(ctx context.Context, term string, opts SearchOpts) ([]*Airport, error) {
 	if !isValidSearchTerm(term) {
 		return nil, ErrInvalidTerm
 	}
 
-	termLike := fmt.Sprintf("%%%s%%", term)
-
-	rows, err := s.db.Query(`
-		SELECT
-			code, name, city, state, lat, lng
-		FROM
-			airports
-		WHERE
-			is_active=1 AND (
-				name LIKE ? OR
-				city LIKE ? OR
-				code LIKE ?
-			)
-	`, termLike, termLike, termLike)
-	if err != nil {
-		return nil, err
+	if opts.First < 0 {
+		return nil, ErrInvalidLimit
 	}
-	defer rows.Close()
-
-	results := []*Airport{}
-	for rows.Next() {
-		var a Airport
-		err := rows.Scan(&a.Code, &a.Name, &a.City, &a.State, &a.Latitude, &a.Longitude)
-		if err != nil {
-			return nil, err
+
+	return paginate(s.airports.search(term), opts)
+}
+
+// paginate returns the page of airports described by opts.
+func paginate(airports []*Airport, opts SearchOpts) ([]*Airport, error) {
+	if opts.After != "" {
+		after := strings.ToUpper(opts.After)
+
+		found := false
+		for i, a := range airports {
+			if a.Code == after {
+				airports = airports[i+1:]
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, ErrInvalidCursor
 		}
+	}
 
-		results = append(results, &a)
+	if opts.First > 0 && len(airports) > opts.First {
+		airports = airports[:opts.First]
 	}
 
-	return results, nil
+	return airports, nil
 }
 
 func isAirportCode(code string) bool {
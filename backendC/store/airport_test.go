@@ -30,6 +30,7 @@ func TestAirport(t *testing.T) {
 	}
 
 	store := New()
+	defer store.Close()
 
 	assert := assert.New(t)
 
@@ -44,18 +45,45 @@ func TestAirport(t *testing.T) {
 }
 
 func TestAirportSearch(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	// Each airport matches "jack" by the start of its city, so they're
+	// ranked by traffic.
+	rows, err := store.db.Query(`
+		SELECT code
+		FROM airports
+		WHERE code IN ('JAC', 'JAN', 'JAX', 'OAJ')
+		ORDER BY total_flights DESC, code`)
+	if !assert.NoError(err) {
+		return
+	}
+	defer rows.Close()
+
+	jack := []string{}
+	for rows.Next() {
+		var code string
+		if !assert.NoError(rows.Scan(&code)) {
+			return
+		}
+		jack = append(jack, code)
+	}
+	if !assert.NoError(rows.Err()) {
+		return
+	}
+
 	cases := []struct {
 		term          string
 		expectedCodes []string
 	}{
 		{
-			term: "jack",
-			expectedCodes: []string{
-				"JAC",
-				"JAN",
-				"JAX",
-				"OAJ",
-			},
+			term:          "jack",
+			expectedCodes: jack,
+		},
+		{
+			term:          "jackson hole",
+			expectedCodes: []string{"JAC"},
 		},
 		{
 			term:          "XYZ",
@@ -63,11 +91,8 @@ func TestAirportSearch(t *testing.T) {
 		},
 	}
 
-	store := New()
-	assert := assert.New(t)
-
 	for _, c := range cases {
-		actual, err := store.AirportSearch(context.Background(), c.term)
+		actual, err := store.AirportSearch(context.Background(), c.term, SearchOpts{})
 		if !assert.NoError(err) {
 			continue
 		}
@@ -77,6 +102,81 @@ func TestAirportSearch(t *testing.T) {
 			actualCodes[i] = airport.Code
 		}
 
-		assert.Equal(c.expectedCodes, actualCodes)
+		assert.Equal(c.expectedCodes, actualCodes, c.term)
+	}
+}
+
+func TestAirportSearchRanking(t *testing.T) {
+	cases := []struct {
+		term     string
+		expected string
+	}{
+		// Exact code match before everything else.
+		{term: "las", expected: "LAS"},
+		// City prefix before name substring.
+		{term: "denver", expected: "DEN"},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.AirportSearch(context.Background(), c.term, SearchOpts{})
+		if !assert.NoError(err) || !assert.NotEmpty(actual) {
+			continue
+		}
+
+		assert.Equal(c.expected, actual[0].Code)
+	}
+}
+
+func TestAirportSearchPagination(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	all, err := store.AirportSearch(context.Background(), "a", SearchOpts{})
+	if !assert.NoError(err) || !assert.True(len(all) > 10) {
+		return
+	}
+
+	var paged []*Airport
+	opts := SearchOpts{First: 10}
+	for {
+		page, err := store.AirportSearch(context.Background(), "a", opts)
+		if !assert.NoError(err) || len(page) == 0 {
+			break
+		}
+
+		assert.LessOrEqual(len(page), 10)
+		paged = append(paged, page...)
+		opts.After = page[len(page)-1].Code
+	}
+
+	assert.Equal(all, paged)
+}
+
+func TestPaginate(t *testing.T) {
+	airports := []*Airport{{Code: "LAS"}, {Code: "LAX"}, {Code: "SFO"}, {Code: "DEN"}}
+
+	cases := []struct {
+		opts     SearchOpts
+		expected []*Airport
+		err      error
+	}{
+		{opts: SearchOpts{}, expected: airports},
+		{opts: SearchOpts{First: 2}, expected: airports[:2]},
+		{opts: SearchOpts{First: 2, After: "lax"}, expected: airports[2:]},
+		{opts: SearchOpts{After: "DEN"}, expected: []*Airport{}},
+		{opts: SearchOpts{After: "JFK"}, err: ErrInvalidCursor},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := paginate(airports, c.opts)
+		assert.Equal(c.err, err)
+		assert.Equal(c.expected, actual)
 	}
 }
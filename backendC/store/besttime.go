@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultBestTimesMinFlights is the number of flights a window needs to be
+// considered by BestTimesToFly when BestTimesOpts.MinFlights is zero.
+const DefaultBestTimesMinFlights = 10
+
+// BestTimesOpts contains options for BestTimesToFly.
+type BestTimesOpts struct {
+	// From and To limit the flights to a date range. Either may be zero
+	// to leave that end of the range open.
+	From, To time.Time
+
+	// OnTimeThreshold is the number of minutes a flight can arrive late
+	// and still be considered on time. Zero means
+	// DefaultOnTimeThreshold.
+	OnTimeThreshold int
+
+	// MinFlights excludes windows with fewer flights, since a handful of
+	// flights says little about a window. Zero means
+	// DefaultBestTimesMinFlights.
+	MinFlights int
+
+	// Limit is the most windows to return. Zero means no limit.
+	Limit int
+}
+
+// TimeWindow contains the stats for an airline's flights that are scheduled
+// to depart in the same hour on the same day of the week.
+type TimeWindow struct {
+	// Carrier is the airline's code.
+	Carrier string `json:"carrier"`
+
+	// Airline is the airline's name.
+	Airline string `json:"airline"`
+
+	// DayOfWeek is the day of the scheduled departure, as a time.Weekday
+	// (0 is Sunday).
+	DayOfWeek int `json:"dayOfWeek"`
+
+	// Hour is the hour (0-23) of the scheduled departure.
+	Hour int `json:"hour"`
+
+	// Stats contains the totals for the window. Its Flights is the sample
+	// size. MedianDelay, P90Delay and DelayHistogram are not set.
+	Stats StatsRow `json:"stats"`
+}
+
+// BestTimesToFly returns the days of the week and hours of the day when each
+// airline's flights from an origin to a destination are most reliable. The
+// windows are ranked from the highest on-time percentage to the lowest, then
+// from the lowest average delay to the highest.
+//
+// Since flights_day doesn't have departure times, this reads from the flights
+// table and is slower than FlightStats.
+//
+// origin and destination are IATA airport codes or metro area codes. If either
+// is invalid ErrInvalidAirportCode is returned. If opts.To is before opts.From
+// ErrInvalidDateRange is returned. If opts.OnTimeThreshold is negative
+// ErrInvalidOnTimeThreshold is returned, and if opts.Limit or opts.MinFlights
+// is negative ErrInvalidLimit is returned.
+func (s *Store) BestTimesToFly(ctx context.Context, origin, destination string, opts BestTimesOpts) ([]*TimeWindow, error) {
+	if !isLocationCode(origin) || !isLocationCode(destination) {
+		return nil, ErrInvalidAirportCode
+	}
+
+	if !isValidDateRange(opts.From, opts.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	threshold := opts.OnTimeThreshold
+	if threshold < 0 {
+		return nil, ErrInvalidOnTimeThreshold
+	} else if threshold == 0 {
+		threshold = DefaultOnTimeThreshold
+	}
+
+	if opts.Limit < 0 || opts.MinFlights < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	minFlights := opts.MinFlights
+	if minFlights == 0 {
+		minFlights = DefaultBestTimesMinFlights
+	}
+
+	from, err := s.location(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.location(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	originCond, args := from.condition("origin")
+	destCond, destArgs := to.condition("destination")
+	where := []string{originCond, destCond}
+	args = append(args, destArgs...)
+
+	dateWhere, dateArgs := dateRangeWhere(opts.From, opts.To)
+	where = append(where, dateWhere...)
+	args = append(args, dateArgs...)
+
+	cols := statsColumns(threshold)
+
+	// DAYOFWEEK starts at 1 for Sunday, time.Weekday starts at 0.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			carriers.code,
+			carriers.name,
+			DAYOFWEEK(date)-1 AS day_of_week,
+			`+departureHourSQL+` AS hour,
+			MIN(date),
+			MAX(date),
+			`+statsSelect(cols, true)+`
+		FROM
+			flights
+			INNER JOIN carriers ON carrier=carriers.code
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY carriers.code, carriers.name, day_of_week, hour
+		HAVING COUNT(*) >= ?`,
+		append(args, minFlights)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []*TimeWindow{}
+	for rows.Next() {
+		var w TimeWindow
+
+		dest := append([]interface{}{&w.Carrier, &w.Airline, &w.DayOfWeek, &w.Hour, &w.Stats.Start, &w.Stats.End}, w.Stats.scanDest(cols)...)
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		w.Stats.DelayCauses.setShares(w.Stats.Delays)
+		windows = append(windows, &w)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	sortTimeWindows(windows)
+
+	if opts.Limit > 0 && len(windows) > opts.Limit {
+		windows = windows[:opts.Limit]
+	}
+
+	return windows, nil
+}
+
+// sortTimeWindows sorts windows from the highest on-time percentage to the
+// lowest. Ties are broken by the lowest average delay, then by the most
+// flights.
+func sortTimeWindows(windows []*TimeWindow) {
+	sort.Slice(windows, func(i, j int) bool {
+		a, b := &windows[i].Stats, &windows[j].Stats
+		if a.OnTime() != b.OnTime() {
+			return a.OnTime() > b.OnTime()
+		}
+
+		if a.AverageDelay != b.AverageDelay {
+			return a.AverageDelay < b.AverageDelay
+		}
+
+		if a.Flights != b.Flights {
+			return a.Flights > b.Flights
+		}
+
+		wa, wb := windows[i], windows[j]
+		if wa.Carrier != wb.Carrier {
+			return wa.Carrier < wb.Carrier
+		}
+
+		if wa.DayOfWeek != wb.DayOfWeek {
+			return wa.DayOfWeek < wb.DayOfWeek
+		}
+
+		return wa.Hour < wb.Hour
+	})
+}
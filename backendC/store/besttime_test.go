@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBestTimesToFly(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	actual, err := store.BestTimesToFly(context.Background(), "LAS", "JFK", BestTimesOpts{MinFlights: 5, Limit: 20})
+	if !assert.NoError(err) || !assert.NotEmpty(actual) {
+		return
+	}
+
+	assert.LessOrEqual(len(actual), 20)
+
+	for i, w := range actual {
+		assert.NotEmpty(w.Carrier)
+		assert.True(w.DayOfWeek >= 0 && w.DayOfWeek < 7)
+		assert.True(w.Hour >= 0 && w.Hour < 24)
+		assert.GreaterOrEqual(w.Stats.Flights, 5)
+
+		if i > 0 {
+			assert.GreaterOrEqual(actual[i-1].Stats.OnTime(), w.Stats.OnTime())
+		}
+	}
+}
+
+func TestBestTimesToFlyInvalid(t *testing.T) {
+	cases := []struct {
+		origin, dest string
+		opts         BestTimesOpts
+		expected     error
+	}{
+		{origin: "LA", dest: "JFK", expected: ErrInvalidAirportCode},
+		{origin: "LAS", dest: "JFK", opts: BestTimesOpts{OnTimeThreshold: -1}, expected: ErrInvalidOnTimeThreshold},
+		{origin: "LAS", dest: "JFK", opts: BestTimesOpts{Limit: -1}, expected: ErrInvalidLimit},
+		{origin: "LAS", dest: "JFK", opts: BestTimesOpts{MinFlights: -1}, expected: ErrInvalidLimit},
+	}
+
+	store := &Store{}
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		_, err := store.BestTimesToFly(context.Background(), c.origin, c.dest, c.opts)
+		assert.Equal(c.expected, err)
+	}
+}
+
+func TestSortTimeWindows(t *testing.T) {
+	windows := []*TimeWindow{
+		{Carrier: "AA", Hour: 6, Stats: StatsRow{Flights: 10, Delays: 5}},
+		{Carrier: "AA", Hour: 7, Stats: StatsRow{Flights: 10, Delays: 1, AverageDelay: 4}},
+		{Carrier: "UA", Hour: 7, Stats: StatsRow{Flights: 10, Delays: 1, AverageDelay: -2}},
+		{Carrier: "DL", Hour: 9, Stats: StatsRow{Flights: 20, Delays: 2, AverageDelay: -2}},
+	}
+
+	sortTimeWindows(windows)
+
+	actual := make([]string, len(windows))
+	for i, w := range windows {
+		actual[i] = w.Carrier
+	}
+
+	assert.Equal(t, []string{"DL", "UA", "AA", "AA"}, actual)
+	assert.Equal(t, 6, windows[3].Hour)
+}
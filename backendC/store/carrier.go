@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Carrier is an airline.
+type Carrier struct {
+	// Code is the airline's code (e.g. "WN").
+	Code string `json:"code"`
+
+	// Name is the airline's name (e.g. "Southwest Airlines Co.").
+	Name string `json:"name"`
+}
+
+// Carrier looks up a single Carrier by its code.
+//
+// If the carrier is not found a nil Carrier is returned.
+//
+// If the carrier code is invalid ErrInvalidCarrierCode is returned.
+func (s *Store) Carrier(ctx context.Context, code string) (*Carrier, error) {
+	code = strings.ToUpper(code)
+	if !isCarrierCode(code) {
+		return nil, ErrInvalidCarrierCode
+	}
+
+	var c Carrier
+	err := s.db.QueryRowContext(ctx, `
+		SELECT code, name
+		FROM carriers
+		WHERE code=?`,
+		code).Scan(&c.Code, &c.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching carrier: %w", err)
+	}
+
+	return &c, nil
+}
+
+// Carriers returns every carrier, sorted by name.
+func (s *Store) Carriers(ctx context.Context) ([]*Carrier, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT code, name
+		FROM carriers
+		ORDER BY name, code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carriers := []*Carrier{}
+	for rows.Next() {
+		var c Carrier
+		err := rows.Scan(&c.Code, &c.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		carriers = append(carriers, &c)
+	}
+
+	return carriers, rows.Err()
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarrier(t *testing.T) {
+	cases := []struct {
+		code     string
+		expected *Carrier
+	}{
+		{
+			code:     "wn",
+			expected: &Carrier{Code: "WN", Name: "Southwest Airlines Co."},
+		},
+		{
+			code:     "ZZ",
+			expected: nil,
+		},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.Carrier(context.Background(), c.code)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		assert.Equal(c.expected, actual)
+	}
+
+	_, err := store.Carrier(context.Background(), "W$")
+	assert.Equal(ErrInvalidCarrierCode, err)
+}
+
+func TestCarriers(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	actual, err := store.Carriers(context.Background())
+	if !assert.NoError(err) || !assert.NotEmpty(actual) {
+		return
+	}
+
+	codes := map[string]bool{}
+	for i, c := range actual {
+		codes[c.Code] = true
+		if i > 0 {
+			assert.LessOrEqual(actual[i-1].Name, c.Name)
+		}
+	}
+
+	assert.True(codes["WN"])
+	assert.True(codes["UA"])
+}
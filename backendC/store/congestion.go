@@ -0,0 +1,337 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CongestionOpts contains options for AirportCongestion.
+type CongestionOpts struct {
+	// From and To limit the flights to a date range. Either may be zero
+	// to leave that end of the range open.
+	From, To time.Time
+}
+
+// AirportCongestion contains taxi times and traffic for an airport.
+type AirportCongestion struct {
+	// ByHour contains the taxi times for each airline and hour of the
+	// day, ordered by airline name then hour.
+	ByHour []HourlyCongestion `json:"byHour"`
+
+	// ByMonth contains the taxi times for each airline and month, ordered
+	// by airline name then month.
+	ByMonth []MonthlyCongestion `json:"byMonth"`
+
+	// Volume contains the number of flights in each hour of the day that
+	// had any flights, ordered by hour.
+	Volume []HourlyVolume `json:"volume"`
+}
+
+// HourlyCongestion contains an airline's taxi times in one hour of the day.
+type HourlyCongestion struct {
+	Carrier string `json:"carrier"`
+	Airline string `json:"airline"`
+
+	// Hour is the hour (0-23) of the scheduled departure for TaxiOut and
+	// of the scheduled arrival for TaxiIn.
+	Hour int `json:"hour"`
+
+	TaxiOut TaxiTimes `json:"taxiOut"`
+	TaxiIn  TaxiTimes `json:"taxiIn"`
+}
+
+// MonthlyCongestion contains an airline's taxi times in one month.
+type MonthlyCongestion struct {
+	Carrier string `json:"carrier"`
+	Airline string `json:"airline"`
+
+	// Month is the first day of the month.
+	Month time.Time `json:"month"`
+
+	TaxiOut TaxiTimes `json:"taxiOut"`
+	TaxiIn  TaxiTimes `json:"taxiIn"`
+}
+
+// TaxiTimes summarizes the minutes flights spent taxiing.
+type TaxiTimes struct {
+	// Flights is the number of flights included.
+	Flights int `json:"flights"`
+
+	// Average is the mean taxi time in minutes.
+	Average float64 `json:"average"`
+
+	// P90 is the estimated 90th percentile taxi time in minutes.
+	P90 float64 `json:"p90"`
+}
+
+// HourlyVolume is the number of flights scheduled in one hour of the day.
+type HourlyVolume struct {
+	// Hour is the hour of the day (0-23).
+	Hour int `json:"hour"`
+
+	// Departures is the number of flights scheduled to depart in the hour.
+	Departures int `json:"departures"`
+
+	// Arrivals is the number of flights scheduled to arrive in the hour.
+	Arrivals int `json:"arrivals"`
+}
+
+// AirportCongestion returns how long flights taxied at an airport and how busy
+// it is through the day. Taxi-out times are for departures from the airport and
+// taxi-in times are for arrivals. Cancelled flights aren't included, and
+// neither are the taxi-in times of diverted flights.
+//
+// Since flights_day doesn't have taxi times, this reads from the flights table
+// and is slower than AirportStats.
+//
+// code is an IATA airport code. If it's invalid ErrInvalidAirportCode is
+// returned. If opts.To is before opts.From ErrInvalidDateRange is returned.
+func (s *Store) AirportCongestion(ctx context.Context, code string, opts CongestionOpts) (*AirportCongestion, error) {
+	code = strings.ToUpper(code)
+	if !isAirportCode(code) {
+		return nil, ErrInvalidAirportCode
+	}
+
+	if !isValidDateRange(opts.From, opts.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	dateWhere, dateArgs := dateRangeWhere(opts.From, opts.To)
+
+	month := "DATE_FORMAT(date, '%Y-%m-01')"
+
+	outWhere := append([]string{"origin=?", "NOT cancelled"}, dateWhere...)
+	inWhere := append([]string{"destination=?", "NOT cancelled", "NOT diverted"}, dateWhere...)
+	args := append([]interface{}{code}, dateArgs...)
+
+	names := map[string]string{}
+
+	outByHour, err := s.taxiTimes(ctx, "taxi_out_time", departureHourSQL, outWhere, args, names)
+	if err != nil {
+		return nil, err
+	}
+
+	inByHour, err := s.taxiTimes(ctx, "taxi_in_time", arrivalHourSQL, inWhere, args, names)
+	if err != nil {
+		return nil, err
+	}
+
+	outByMonth, err := s.taxiTimes(ctx, "taxi_out_time", month, outWhere, args, names)
+	if err != nil {
+		return nil, err
+	}
+
+	inByMonth, err := s.taxiTimes(ctx, "taxi_in_time", month, inWhere, args, names)
+	if err != nil {
+		return nil, err
+	}
+
+	congestion := &AirportCongestion{
+		ByHour:  []HourlyCongestion{},
+		ByMonth: []MonthlyCongestion{},
+	}
+
+	for _, key := range taxiKeys(outByHour, inByHour) {
+		hour, err := strconv.Atoi(key[1])
+		if err != nil {
+			return nil, err
+		}
+
+		congestion.ByHour = append(congestion.ByHour, HourlyCongestion{
+			Carrier: key[0],
+			Airline: names[key[0]],
+			Hour:    hour,
+			TaxiOut: outByHour[key].times(),
+			TaxiIn:  inByHour[key].times(),
+		})
+	}
+
+	for _, key := range taxiKeys(outByMonth, inByMonth) {
+		m, err := time.Parse(dateFormat, key[1])
+		if err != nil {
+			return nil, err
+		}
+
+		congestion.ByMonth = append(congestion.ByMonth, MonthlyCongestion{
+			Carrier: key[0],
+			Airline: names[key[0]],
+			Month:   m,
+			TaxiOut: outByMonth[key].times(),
+			TaxiIn:  inByMonth[key].times(),
+		})
+	}
+
+	sort.SliceStable(congestion.ByHour, func(i, j int) bool {
+		a, b := &congestion.ByHour[i], &congestion.ByHour[j]
+		if a.Airline != b.Airline {
+			return a.Airline < b.Airline
+		}
+
+		if a.Carrier != b.Carrier {
+			return a.Carrier < b.Carrier
+		}
+
+		return a.Hour < b.Hour
+	})
+
+	sort.SliceStable(congestion.ByMonth, func(i, j int) bool {
+		a, b := &congestion.ByMonth[i], &congestion.ByMonth[j]
+		if a.Airline != b.Airline {
+			return a.Airline < b.Airline
+		}
+
+		if a.Carrier != b.Carrier {
+			return a.Carrier < b.Carrier
+		}
+
+		return a.Month.Before(b.Month)
+	})
+
+	congestion.Volume, err = s.hourlyVolume(ctx, code, dateWhere, dateArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	return congestion, nil
+}
+
+// taxiTotals accumulates taxi times.
+type taxiTotals struct {
+	dist    delayDistribution
+	minutes int
+}
+
+// times returns the summary of the totals. It can be called on nil, which has
+// no flights.
+func (t *taxiTotals) times() TaxiTimes {
+	if t == nil || t.dist.total == 0 {
+		return TaxiTimes{}
+	}
+
+	return TaxiTimes{
+		Flights: t.dist.total,
+		Average: float64(t.minutes) / float64(t.dist.total),
+		P90:     t.dist.Percentile(90),
+	}
+}
+
+// taxiTimes returns the taxi times in column for the flights matching where,
+// keyed by carrier code and the value of keyExpr. The name of each carrier is
+// added to names.
+func (s *Store) taxiTimes(ctx context.Context, column, keyExpr string, where []string, args []interface{}, names map[string]string) (map[[2]string]*taxiTotals, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			carriers.code,
+			carriers.name,
+			%[1]s AS group_key,
+			FLOOR(%[2]s/%[3]d)*%[3]d AS bucket,
+			COUNT(*),
+			SUM(%[2]s)
+		FROM
+			flights
+			INNER JOIN carriers ON carrier=carriers.code
+		WHERE %[4]s AND %[2]s IS NOT NULL
+		GROUP BY carriers.code, carriers.name, group_key, bucket`,
+		keyExpr,
+		column,
+		delayBucketMinutes,
+		strings.Join(where, " AND ")),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[[2]string]*taxiTotals{}
+	for rows.Next() {
+		var (
+			carrier, name, key       string
+			bucket, flights, minutes int
+		)
+
+		err := rows.Scan(&carrier, &name, &key, &bucket, &flights, &minutes)
+		if err != nil {
+			return nil, err
+		}
+
+		names[carrier] = name
+
+		k := [2]string{carrier, key}
+		if totals[k] == nil {
+			totals[k] = &taxiTotals{}
+		}
+		totals[k].dist.Add(bucket, flights)
+		totals[k].minutes += minutes
+	}
+
+	return totals, rows.Err()
+}
+
+// taxiKeys returns the keys that are in either a or b.
+func taxiKeys(a, b map[[2]string]*taxiTotals) [][2]string {
+	keys := make([][2]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// hourlyVolume returns the number of flights scheduled to depart from and
+// arrive at an airport in each hour of the day.
+func (s *Store) hourlyVolume(ctx context.Context, code string, dateWhere []string, dateArgs []interface{}) ([]HourlyVolume, error) {
+	dateCond := ""
+	if len(dateWhere) > 0 {
+		dateCond = " AND " + strings.Join(dateWhere, " AND ")
+	}
+
+	args := append([]interface{}{code}, dateArgs...)
+	args = append(args, code)
+	args = append(args, dateArgs...)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT hour, SUM(departures), SUM(arrivals)
+		FROM (
+			SELECT `+departureHourSQL+` AS hour, COUNT(*) AS departures, 0 AS arrivals
+			FROM flights
+			WHERE origin=?`+dateCond+`
+			GROUP BY hour
+
+			UNION ALL
+
+			SELECT `+arrivalHourSQL+` AS hour, 0 AS departures, COUNT(*) AS arrivals
+			FROM flights
+			WHERE destination=?`+dateCond+`
+			GROUP BY hour
+		) hours
+		GROUP BY hour
+		ORDER BY hour`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	volume := []HourlyVolume{}
+	for rows.Next() {
+		var v HourlyVolume
+		err := rows.Scan(&v.Hour, &v.Departures, &v.Arrivals)
+		if err != nil {
+			return nil, err
+		}
+
+		volume = append(volume, v)
+	}
+
+	return volume, rows.Err()
+}
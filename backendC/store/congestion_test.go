@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirportCongestion(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	actual, err := store.AirportCongestion(context.Background(), "LAS", CongestionOpts{})
+	if !assert.NoError(err) || !assert.NotNil(actual) {
+		return
+	}
+
+	assert.NotEmpty(actual.ByHour)
+	for _, row := range actual.ByHour {
+		assert.NotEmpty(row.Airline)
+		assert.True(row.Hour >= 0 && row.Hour < 24)
+		assert.True(row.TaxiOut.Flights > 0 || row.TaxiIn.Flights > 0)
+		assert.GreaterOrEqual(row.TaxiOut.P90, 0.0)
+	}
+
+	assert.NotEmpty(actual.ByMonth)
+	for _, row := range actual.ByMonth {
+		assert.Equal(1, row.Month.Day())
+	}
+
+	assert.NotEmpty(actual.Volume)
+	for i, v := range actual.Volume {
+		assert.True(v.Departures > 0 || v.Arrivals > 0)
+		if i > 0 {
+			assert.Greater(v.Hour, actual.Volume[i-1].Hour)
+		}
+	}
+}
+
+func TestAirportCongestionInvalid(t *testing.T) {
+	store := &Store{}
+	assert := assert.New(t)
+
+	_, err := store.AirportCongestion(context.Background(), "LASX", CongestionOpts{})
+	assert.Equal(ErrInvalidAirportCode, err)
+
+	_, err = store.AirportCongestion(context.Background(), "LAS", CongestionOpts{
+		From: time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.Equal(ErrInvalidDateRange, err)
+}
+
+func TestTaxiTotalsTimes(t *testing.T) {
+	var totals taxiTotals
+	totals.dist.Add(5, 3)
+	totals.dist.Add(20, 1)
+	totals.minutes = 3*6 + 22
+
+	actual := totals.times()
+
+	assert := assert.New(t)
+	assert.Equal(4, actual.Flights)
+	assert.InDelta(10, actual.Average, 0.0001)
+	assert.InDelta(23, actual.P90, 0.0001)
+
+	var empty *taxiTotals
+	assert.Equal(TaxiTimes{}, empty.times())
+}
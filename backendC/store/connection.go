@@ -0,0 +1,305 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMinConnectionTime is the minimum connection time in minutes that's
+// used when ConnectionOpts.MinConnectionTime is nil.
+const DefaultMinConnectionTime = 45
+
+// maxConnectionTime is the longest layover in minutes that's considered a
+// connection.
+const maxConnectionTime = 240
+
+// ConnectionOpts contains options for ConnectingRoutes.
+type ConnectionOpts struct {
+	// From and To limit the flights to a date range. Either may be zero
+	// to leave that end of the range open.
+	From, To time.Time
+
+	// OnTimeThreshold is the number of minutes a flight can arrive late
+	// and still be considered on time. Zero means
+	// DefaultOnTimeThreshold.
+	OnTimeThreshold int
+
+	// MinConnectionTime is the least number of minutes between the
+	// scheduled arrival of the first flight and the scheduled departure
+	// of the second. Nil means DefaultMinConnectionTime, so that zero
+	// can be used for no minimum.
+	MinConnectionTime *int
+
+	// MaxStops is the most stops an itinerary can have. Only one-stop
+	// itineraries are supported, so it must be 1.
+	MaxStops int
+}
+
+// Connection contains information about a one-stop itinerary.
+type Connection struct {
+	// Hub is the IATA code of the airport where passengers connect.
+	Hub string `json:"hub"`
+
+	// First contains the totals for flights from the origin to Hub.
+	First StatsRow `json:"first"`
+
+	// Second contains the totals for flights from Hub to the destination.
+	Second StatsRow `json:"second"`
+
+	// OnTime is the percentage chance that both flights are on time,
+	// assuming they're independent.
+	OnTime float64 `json:"onTimePercentage"`
+
+	// ConnectionDays is the number of days with at least one pair of
+	// scheduled flights that meet the minimum connection time.
+	ConnectionDays int `json:"connectionDays"`
+}
+
+// Feasible returns true if the schedule ever allowed a connection at the hub.
+func (c *Connection) Feasible() bool {
+	return c.ConnectionDays > 0
+}
+
+// ConnectingRoutes returns the hubs that connect an origin to a destination.
+// A hub must have flight data from the origin and to the destination.
+//
+// The results are ranked with feasible connections first, then by the
+// probability that both flights are on time.
+//
+// origin and destination are IATA airport codes or metro area codes. If either
+// is invalid ErrInvalidAirportCode is returned. Airports in the origin or
+// destination metro area aren't considered as hubs.
+//
+// If opts.To is before opts.From ErrInvalidDateRange is returned. If
+// opts.OnTimeThreshold is negative ErrInvalidOnTimeThreshold is returned. If
+// opts.MinConnectionTime is negative ErrInvalidConnectionTime is returned, and
+// if opts.MaxStops is not 1 ErrInvalidMaxStops is returned.
+func (s *Store) ConnectingRoutes(ctx context.Context, origin, destination string, opts ConnectionOpts) ([]*Connection, error) {
+	if !isLocationCode(origin) || !isLocationCode(destination) {
+		return nil, ErrInvalidAirportCode
+	}
+
+	if !isValidDateRange(opts.From, opts.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	threshold := opts.OnTimeThreshold
+	if threshold < 0 {
+		return nil, ErrInvalidOnTimeThreshold
+	} else if threshold == 0 {
+		threshold = DefaultOnTimeThreshold
+	}
+
+	minConnection := DefaultMinConnectionTime
+	if opts.MinConnectionTime != nil {
+		minConnection = *opts.MinConnectionTime
+	}
+
+	if minConnection < 0 {
+		return nil, ErrInvalidConnectionTime
+	}
+
+	if opts.MaxStops != 1 {
+		return nil, ErrInvalidMaxStops
+	}
+
+	from, err := s.location(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.location(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	dateWhere, dateArgs := dateRangeWhere(opts.From, opts.To)
+
+	cols := statsColumns(threshold)
+
+	originCond, originArgs := from.condition("origin")
+	first, err := s.routeTotals(ctx, cols, "destination", originCond, originArgs, dateWhere, dateArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	destCond, destArgs := to.condition("destination")
+	second, err := s.routeTotals(ctx, cols, "origin", destCond, destArgs, dateWhere, dateArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	connections := []*Connection{}
+	for hub, firstRow := range first {
+		if from.contains(hub) || to.contains(hub) {
+			continue
+		}
+
+		secondRow, ok := second[hub]
+		if !ok {
+			continue
+		}
+
+		connections = append(connections, &Connection{
+			Hub:    hub,
+			First:  firstRow,
+			Second: secondRow,
+			OnTime: firstRow.OnTime() * secondRow.OnTime() / 100,
+		})
+	}
+
+	if len(connections) == 0 {
+		return connections, nil
+	}
+
+	days, err := s.connectionDays(ctx, from, to, minConnection, connections, dateWhere, dateArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range connections {
+		c.ConnectionDays = days[c.Hub]
+	}
+
+	sort.Slice(connections, func(i, j int) bool {
+		a, b := connections[i], connections[j]
+		if a.Feasible() != b.Feasible() {
+			return a.Feasible()
+		}
+
+		if a.OnTime != b.OnTime {
+			return a.OnTime > b.OnTime
+		}
+
+		return a.Hub < b.Hub
+	})
+
+	return connections, nil
+}
+
+// routeTotals returns the stats for all flights matching cond, which is an SQL
+// condition with placeholders for condArgs, grouped by the airport in
+// groupColumn.
+func (s *Store) routeTotals(ctx context.Context, cols []statsColumn, groupColumn, cond string, condArgs []interface{}, dateWhere []string, dateArgs []interface{}) (map[string]StatsRow, error) {
+	raw := !hasRollup(cols)
+	table := "flights_day"
+	if raw {
+		table = "flights"
+	}
+
+	where := append([]string{cond}, dateWhere...)
+	args := append(condArgs[:len(condArgs):len(condArgs)], dateArgs...)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			%s,
+			MIN(date),
+			MAX(date),
+			%s
+		FROM %s
+		WHERE %s
+		GROUP BY %s`,
+		groupColumn,
+		statsSelect(cols, raw),
+		table,
+		strings.Join(where, " AND "),
+		groupColumn),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]StatsRow{}
+	for rows.Next() {
+		var (
+			airport string
+			row     StatsRow
+		)
+
+		dest := append([]interface{}{&airport, &row.Start, &row.End}, row.scanDest(cols)...)
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		row.DelayCauses.setShares(row.Delays)
+		totals[airport] = row
+	}
+
+	return totals, rows.Err()
+}
+
+// connectionDays returns the number of days where a scheduled flight from
+// an airport in from to each hub connects with a scheduled flight from the hub
+// to an airport in to, keyed by hub. A connection must have between minConnection
+// and maxConnectionTime minutes between the scheduled times.
+//
+// First flights scheduled to arrive after midnight are ignored.
+func (s *Store) connectionDays(ctx context.Context, from, to *Location, minConnection int, connections []*Connection, dateWhere []string, dateArgs []interface{}) (map[string]int, error) {
+	originCond, originArgs := from.condition("a.origin")
+	destCond, destArgs := to.condition("b.destination")
+
+	args := []interface{}{minConnection * 60, maxConnectionTime * 60}
+	args = append(args, originArgs...)
+	args = append(args, destArgs...)
+
+	hubs := make([]string, len(connections))
+	for i, c := range connections {
+		hubs[i] = "?"
+		args = append(args, c.Hub)
+	}
+
+	where := []string{
+		originCond,
+		destCond,
+		fmt.Sprintf("a.destination IN (%s)", strings.Join(hubs, ",")),
+		"NOT a.cancelled",
+		"NOT b.cancelled",
+		"a.scheduled_arrival_time > a.scheduled_departure_time",
+	}
+	for _, cond := range dateWhere {
+		where = append(where, "a."+cond)
+	}
+	args = append(args, dateArgs...)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			a.destination,
+			COUNT(DISTINCT a.date)
+		FROM
+			flights a
+			INNER JOIN flights b ON
+				b.origin=a.destination
+				AND b.date=a.date
+				AND b.scheduled_departure_time BETWEEN
+					ADDTIME(a.scheduled_arrival_time, SEC_TO_TIME(?))
+					AND ADDTIME(a.scheduled_arrival_time, SEC_TO_TIME(?))
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY a.destination`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := map[string]int{}
+	for rows.Next() {
+		var (
+			hub   string
+			count int
+		)
+
+		err := rows.Scan(&hub, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		days[hub] = count
+	}
+
+	return days, rows.Err()
+}
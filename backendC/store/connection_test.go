@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectingRoutes(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	actual, err := store.ConnectingRoutes(context.Background(), "LAS", "JFK", ConnectionOpts{MaxStops: 1})
+	if !assert.NoError(err) || !assert.NotEmpty(actual) {
+		return
+	}
+
+	for i, c := range actual {
+		assert.NotEqual("LAS", c.Hub)
+		assert.NotEqual("JFK", c.Hub)
+		assert.Greater(c.First.Flights, 0)
+		assert.Greater(c.Second.Flights, 0)
+		assert.InDelta(c.First.OnTime()*c.Second.OnTime()/100, c.OnTime, 0.0001)
+
+		if i == 0 {
+			continue
+		}
+
+		prev := actual[i-1]
+		if prev.Feasible() == c.Feasible() {
+			assert.GreaterOrEqual(prev.OnTime, c.OnTime)
+		} else {
+			assert.True(prev.Feasible())
+		}
+	}
+
+	// A longer minimum connection time can't make more days feasible.
+	days := map[string]int{}
+	for _, c := range actual {
+		days[c.Hub] = c.ConnectionDays
+	}
+
+	minutes := 180
+	actual, err = store.ConnectingRoutes(context.Background(), "LAS", "JFK", ConnectionOpts{MaxStops: 1, MinConnectionTime: &minutes})
+	if !assert.NoError(err) {
+		return
+	}
+
+	for _, c := range actual {
+		assert.LessOrEqual(c.ConnectionDays, days[c.Hub], c.Hub)
+	}
+
+	// Zero means no minimum, which can't make fewer days feasible than
+	// the default.
+	minutes = 0
+	actual, err = store.ConnectingRoutes(context.Background(), "LAS", "JFK", ConnectionOpts{MaxStops: 1, MinConnectionTime: &minutes})
+	if !assert.NoError(err) {
+		return
+	}
+
+	for _, c := range actual {
+		assert.GreaterOrEqual(c.ConnectionDays, days[c.Hub], c.Hub)
+	}
+}
+
+func TestConnectingRoutesInvalid(t *testing.T) {
+	negative := -1
+	cases := []struct {
+		origin, dest string
+		opts         ConnectionOpts
+		expected     error
+	}{
+		{origin: "LA", dest: "JFK", expected: ErrInvalidAirportCode},
+		{origin: "LAS", dest: "JFK", opts: ConnectionOpts{MaxStops: 1, MinConnectionTime: &negative}, expected: ErrInvalidConnectionTime},
+		{origin: "LAS", dest: "JFK", opts: ConnectionOpts{MaxStops: 0}, expected: ErrInvalidMaxStops},
+		{origin: "LAS", dest: "JFK", opts: ConnectionOpts{MaxStops: 2}, expected: ErrInvalidMaxStops},
+		{origin: "LAS", dest: "JFK", opts: ConnectionOpts{MaxStops: 1, OnTimeThreshold: -5}, expected: ErrInvalidOnTimeThreshold},
+	}
+
+	// The arguments are validated before the database is used.
+	store := &Store{}
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		_, err := store.ConnectingRoutes(context.Background(), c.origin, c.dest, c.opts)
+		assert.Equal(c.expected, err)
+	}
+}
@@ -0,0 +1,365 @@
+package store
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDisruptionBaselineDays is the number of days before each day that
+// make up its baseline when DisruptionOpts.BaselineDays is zero.
+const DefaultDisruptionBaselineDays = 28
+
+const (
+	// disruptionSigma is the number of standard deviations below the
+	// baseline an on-time percentage must be to count as a disruption.
+	disruptionSigma = 3.0
+
+	// disruptionMinStdDev is the smallest standard deviation, in
+	// percentage points, used for a baseline. Without it an airport with
+	// a very steady record would be disrupted by a dip of a few points.
+	disruptionMinStdDev = 2.0
+
+	// disruptionMinFlights is the number of departures an airport needs on
+	// a day for the day to be checked or to be part of a baseline.
+	disruptionMinFlights = 20
+)
+
+// DisruptionOpts contains options for Disruptions.
+type DisruptionOpts struct {
+	// From and To limit the disrupted days to a date range. Either may be
+	// zero to leave that end of the range open. Days before From are
+	// still used for the baseline.
+	From, To time.Time
+
+	// BaselineDays is the number of days before each day that make up
+	// its baseline. Zero means DefaultDisruptionBaselineDays.
+	BaselineDays int
+}
+
+// Disruption is an event where one or more airports in a state had disrupted
+// days in a row.
+type Disruption struct {
+	// State is the state the airports are in.
+	State string `json:"state"`
+
+	// Start is the first disrupted day and End is the last.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Airports contains the codes of the disrupted airports, sorted
+	// alphabetically.
+	Airports []string `json:"airports"`
+
+	// Days contains each disrupted day at each airport, ordered by date
+	// then airport.
+	Days []DisruptedDay `json:"days"`
+}
+
+// DisruptedDay is a day when an airport's on-time percentage was far below its
+// baseline.
+type DisruptedDay struct {
+	// Airport is the airport's code.
+	Airport string `json:"airport"`
+
+	// Date is the day of the disruption.
+	Date time.Time `json:"date"`
+
+	// Stats contains the totals for every airline's departures from the
+	// airport on the day. MedianDelay, P90Delay and DelayHistogram are
+	// not set.
+	Stats StatsRow `json:"stats"`
+
+	// Baseline is the mean on-time percentage of the days in the
+	// baseline.
+	Baseline float64 `json:"baseline"`
+
+	// StdDev is the standard deviation of the on-time percentage of the
+	// days in the baseline, in percentage points.
+	StdDev float64 `json:"stdDev"`
+
+	// Score is the number of standard deviations the day's on-time
+	// percentage was from the baseline. It's always negative.
+	Score float64 `json:"score"`
+
+	// Cause is the cause that affected the most flights. See
+	// StatsRow.DominantCause.
+	Cause string `json:"cause"`
+}
+
+// Disruptions finds days when an airport's on-time percentage was more than
+// three standard deviations below its rolling baseline, which is made up of
+// the days before it. Disrupted days are left out of later baselines, so a
+// storm that lasts several days doesn't hide its own later days.
+//
+// Each day is the total of an airport's departures, the same as the rows
+// from AirportStats with GroupByDay added up across airlines. They're read
+// with one query grouped by airport instead, since AirportStats would take
+// several queries for each airport and compute percentiles and trends that
+// aren't needed. Days with few departures are skipped, as are days without
+// enough days in their baseline.
+//
+// Disrupted days in the same state on consecutive dates are grouped into one
+// Disruption, which is how a storm that hits several airports shows up. The
+// disruptions are ordered by start date then state.
+//
+// code is an IATA airport code, a metro area code or a two letter state code.
+// If a state code is invalid ErrInvalidState is returned, and if any other
+// code is invalid ErrInvalidAirportCode is returned. If opts.To is before
+// opts.From ErrInvalidDateRange is returned. If opts.BaselineDays is negative
+// ErrInvalidBaselineDays is returned.
+func (s *Store) Disruptions(ctx context.Context, code string, opts DisruptionOpts) ([]*Disruption, error) {
+	code = strings.ToUpper(code)
+
+	isState := len(code) == 2
+	if isState && !isStateCode(code) {
+		return nil, ErrInvalidState
+	} else if !isState && !isAirportCode(code) {
+		return nil, ErrInvalidAirportCode
+	}
+
+	if !isValidDateRange(opts.From, opts.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	baselineDays := opts.BaselineDays
+	if baselineDays < 0 {
+		return nil, ErrInvalidBaselineDays
+	} else if baselineDays == 0 {
+		baselineDays = DefaultDisruptionBaselineDays
+	}
+
+	var (
+		where []string
+		args  []interface{}
+	)
+
+	if isState {
+		where = append(where, "airport_states.state=?")
+		args = append(args, code)
+	} else {
+		loc, err := s.location(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+
+		cond, condArgs := loc.condition("origin")
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	// The baseline of the first day starts before it.
+	from := opts.From
+	if !from.IsZero() {
+		from = from.AddDate(0, 0, -baselineDays)
+	}
+
+	dateWhere, dateArgs := dateRangeWhere(from, opts.To)
+	where = append(where, dateWhere...)
+	args = append(args, dateArgs...)
+
+	cols := statsColumns(DefaultOnTimeThreshold)
+
+	// airports has a total_flights column too, so only the columns that
+	// are needed are joined.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			origin,
+			airport_states.state,
+			MIN(date),
+			MAX(date),
+			`+statsSelect(cols, false)+`
+		FROM
+			flights_day
+			INNER JOIN (SELECT code, state FROM airports) airport_states ON origin=airport_states.code
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY origin, airport_states.state, date
+		ORDER BY origin, date`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	daily := map[string][]StatsRow{}
+	states := map[string]string{}
+	for rows.Next() {
+		var (
+			airport, state string
+			row            StatsRow
+		)
+
+		dest := append([]interface{}{&airport, &state, &row.Start, &row.End}, row.scanDest(cols)...)
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		row.DelayCauses.setShares(row.Delays)
+		daily[airport] = append(daily[airport], row)
+		states[airport] = state
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	var days []DisruptedDay
+	for airport, airportRows := range daily {
+		days = append(days, disruptedDays(airport, airportRows, opts.From, baselineDays)...)
+	}
+
+	return groupDisruptions(days, states), nil
+}
+
+// disruptedDays returns the disrupted days in rows, which contains an
+// airport's daily totals ordered by date. Days before from are only used for
+// baselines.
+func disruptedDays(airport string, rows []StatsRow, from time.Time, baselineDays int) []DisruptedDay {
+	type baselineDay struct {
+		date   time.Time
+		onTime float64
+	}
+
+	minDays := baselineDays / 2
+	if minDays < 2 {
+		minDays = 2
+	}
+
+	var (
+		days     []DisruptedDay
+		baseline []baselineDay
+	)
+
+	for _, row := range rows {
+		if row.Flights < disruptionMinFlights {
+			continue
+		}
+
+		cutoff := row.Start.AddDate(0, 0, -baselineDays)
+		for len(baseline) > 0 && baseline[0].date.Before(cutoff) {
+			baseline = baseline[1:]
+		}
+
+		onTime := row.OnTime()
+
+		if len(baseline) >= minDays {
+			var sum, sumSquares float64
+			for _, day := range baseline {
+				sum += day.onTime
+				sumSquares += day.onTime * day.onTime
+			}
+
+			n := float64(len(baseline))
+			mean := sum / n
+			stdDev := math.Sqrt(math.Max(sumSquares-n*mean*mean, 0) / (n - 1))
+
+			score := (onTime - mean) / math.Max(stdDev, disruptionMinStdDev)
+			if score < -disruptionSigma {
+				if !row.Start.Before(from) {
+					days = append(days, DisruptedDay{
+						Airport:  airport,
+						Date:     row.Start,
+						Stats:    row,
+						Baseline: mean,
+						StdDev:   stdDev,
+						Score:    score,
+						Cause:    row.DominantCause(),
+					})
+				}
+
+				continue
+			}
+		}
+
+		baseline = append(baseline, baselineDay{date: row.Start, onTime: onTime})
+	}
+
+	return days
+}
+
+// groupDisruptions groups days into disruptions by state and consecutive
+// dates. states maps airport codes to state codes.
+func groupDisruptions(days []DisruptedDay, states map[string]string) []*Disruption {
+	sort.Slice(days, func(i, j int) bool {
+		a, b := &days[i], &days[j]
+		if states[a.Airport] != states[b.Airport] {
+			return states[a.Airport] < states[b.Airport]
+		}
+
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+
+		return a.Airport < b.Airport
+	})
+
+	disruptions := []*Disruption{}
+	var current *Disruption
+
+	for _, day := range days {
+		state := states[day.Airport]
+		if current == nil || current.State != state || day.Date.After(current.End.AddDate(0, 0, 1)) {
+			current = &Disruption{
+				State:    state,
+				Start:    day.Date,
+				Airports: []string{},
+			}
+			disruptions = append(disruptions, current)
+		}
+
+		current.End = day.Date
+		current.Days = append(current.Days, day)
+
+		i := sort.SearchStrings(current.Airports, day.Airport)
+		if i == len(current.Airports) || current.Airports[i] != day.Airport {
+			current.Airports = append(current.Airports, "")
+			copy(current.Airports[i+1:], current.Airports[i:])
+			current.Airports[i] = day.Airport
+		}
+	}
+
+	sort.SliceStable(disruptions, func(i, j int) bool {
+		a, b := disruptions[i], disruptions[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+
+		return a.State < b.State
+	})
+
+	return disruptions
+}
+
+// DominantCause returns the cause that affected the most flights:
+// "carrier", "weather", "nas", "security", "lateAircraft" or "diverted".
+// Flights delayed by a cause and flights cancelled because of it both count
+// towards it, so a day of weather cancellations is put down to the weather.
+// Ties go to the cause with more minutes of delay. It returns an empty string
+// if no flights were delayed, cancelled or diverted.
+func (row *StatsRow) DominantCause() string {
+	dc, c := &row.DelayCauses, &row.Cancellations
+	causes := []struct {
+		name             string
+		flights, minutes int
+	}{
+		{"carrier", dc.Carrier.Flights + c.Carrier, dc.Carrier.Minutes},
+		{"weather", dc.Weather.Flights + c.Weather, dc.Weather.Minutes},
+		{"nas", dc.NAS.Flights + c.NAS, dc.NAS.Minutes},
+		{"security", dc.Security.Flights + c.Security, dc.Security.Minutes},
+		{"lateAircraft", dc.LateAircraft.Flights, dc.LateAircraft.Minutes},
+		{"diverted", row.Diverted, 0},
+	}
+
+	dominant, flights, minutes := "", 0, 0
+	for _, cause := range causes {
+		if cause.flights > flights || (cause.flights == flights && cause.minutes > minutes) {
+			dominant, flights, minutes = cause.name, cause.flights, cause.minutes
+		}
+	}
+
+	return dominant
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisruptions(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	opts := DisruptionOpts{
+		From: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2019, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, code := range []string{"NV", "LAS", "NYC"} {
+		actual, err := store.Disruptions(context.Background(), code, opts)
+		if !assert.NoError(err, code) {
+			continue
+		}
+
+		for i, d := range actual {
+			assert.NotEmpty(d.State)
+			assert.NotEmpty(d.Airports)
+			assert.NotEmpty(d.Days)
+			assert.False(d.Start.Before(opts.From))
+			assert.False(d.End.Before(d.Start))
+
+			if code == "NV" {
+				assert.Equal("NV", d.State)
+			}
+
+			if i > 0 {
+				assert.False(d.Start.Before(actual[i-1].Start))
+			}
+
+			for _, day := range d.Days {
+				assert.Contains(d.Airports, day.Airport)
+				assert.True(day.Stats.OnTime() < day.Baseline)
+				assert.True(day.Score < -disruptionSigma)
+				assert.GreaterOrEqual(day.Stats.Flights, disruptionMinFlights)
+			}
+		}
+	}
+}
+
+func TestDisruptionsInvalid(t *testing.T) {
+	store := &Store{}
+	assert := assert.New(t)
+
+	_, err := store.Disruptions(context.Background(), "LASX", DisruptionOpts{})
+	assert.Equal(ErrInvalidAirportCode, err)
+
+	_, err = store.Disruptions(context.Background(), "N1", DisruptionOpts{})
+	assert.Equal(ErrInvalidState, err)
+
+	_, err = store.Disruptions(context.Background(), "NV", DisruptionOpts{BaselineDays: -1})
+	assert.Equal(ErrInvalidBaselineDays, err)
+
+	_, err = store.Disruptions(context.Background(), "LAS", DisruptionOpts{
+		From: time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.Equal(ErrInvalidDateRange, err)
+}
+
+func TestDisruptedDays(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// 20 normal days alternating between 90% and 86% on time, then a
+	// storm, a normal day, a second storm day and a day with too few
+	// flights to count.
+	var rows []StatsRow
+	for i := 0; i < 20; i++ {
+		delays := 10
+		if i%2 == 1 {
+			delays = 14
+		}
+		rows = append(rows, StatsRow{Start: start.AddDate(0, 0, i), Flights: 100, Delays: delays})
+	}
+
+	storm := StatsRow{
+		Start:     start.AddDate(0, 0, 20),
+		Flights:   100,
+		Delays:    40,
+		Cancelled: 20,
+		Cancellations: Cancellations{
+			Weather: 20,
+		},
+		DelayCauses: DelayCauses{
+			Weather: DelayCause{Minutes: 900, Flights: 20},
+			NAS:     DelayCause{Minutes: 600, Flights: 30},
+		},
+	}
+	rows = append(rows,
+		storm,
+		StatsRow{Start: start.AddDate(0, 0, 21), Flights: 100, Delays: 12},
+		StatsRow{Start: start.AddDate(0, 0, 22), Flights: 100, Delays: 60},
+		StatsRow{Start: start.AddDate(0, 0, 23), Flights: 5, Delays: 5},
+	)
+
+	assert := assert.New(t)
+
+	days := disruptedDays("DEN", rows, time.Time{}, 28)
+	if assert.Len(days, 2) {
+		assert.Equal("DEN", days[0].Airport)
+		assert.Equal(storm.Start, days[0].Date)
+		assert.InDelta(88, days[0].Baseline, 0.001)
+		assert.InDelta(2.05, days[0].StdDev, 0.01)
+		assert.True(days[0].Score < -3)
+		assert.Equal("weather", days[0].Cause)
+
+		// The storm isn't in the second day's baseline.
+		assert.Equal(start.AddDate(0, 0, 22), days[1].Date)
+		assert.InDelta(88, days[1].Baseline, 0.1)
+	}
+
+	// Days before from are only used for baselines.
+	days = disruptedDays("DEN", rows, start.AddDate(0, 0, 21), 28)
+	assert.Len(days, 1)
+
+	// A short baseline that hasn't filled yet.
+	assert.Empty(disruptedDays("DEN", rows[:3], time.Time{}, 28))
+}
+
+func TestGroupDisruptions(t *testing.T) {
+	day := func(airport string, d int) DisruptedDay {
+		return DisruptedDay{Airport: airport, Date: time.Date(2019, time.February, d, 0, 0, 0, 0, time.UTC)}
+	}
+
+	states := map[string]string{"DEN": "CO", "COS": "CO", "ASE": "CO", "LAS": "NV"}
+	days := []DisruptedDay{
+		day("DEN", 11),
+		day("LAS", 10),
+		day("COS", 10),
+		day("DEN", 10),
+		day("ASE", 14),
+	}
+
+	actual := groupDisruptions(days, states)
+
+	assert := assert.New(t)
+	if !assert.Len(actual, 3) {
+		return
+	}
+
+	assert.Equal("CO", actual[0].State)
+	assert.Equal(10, actual[0].Start.Day())
+	assert.Equal(11, actual[0].End.Day())
+	assert.Equal([]string{"COS", "DEN"}, actual[0].Airports)
+	assert.Len(actual[0].Days, 3)
+
+	assert.Equal("NV", actual[1].State)
+	assert.Equal([]string{"LAS"}, actual[1].Airports)
+
+	assert.Equal("CO", actual[2].State)
+	assert.Equal([]string{"ASE"}, actual[2].Airports)
+
+	assert.Empty(groupDisruptions(nil, states))
+}
+
+func TestStatsRowDominantCause(t *testing.T) {
+	cases := []struct {
+		row      StatsRow
+		expected string
+	}{
+		{row: StatsRow{}, expected: ""},
+		{
+			row: StatsRow{DelayCauses: DelayCauses{
+				Carrier: DelayCause{Flights: 2, Minutes: 100},
+				NAS:     DelayCause{Flights: 3, Minutes: 30},
+			}},
+			expected: "nas",
+		},
+		// Ties go to the most minutes.
+		{
+			row: StatsRow{DelayCauses: DelayCauses{
+				Carrier: DelayCause{Flights: 3, Minutes: 100},
+				NAS:     DelayCause{Flights: 3, Minutes: 30},
+			}},
+			expected: "carrier",
+		},
+		// Cancellations count towards their cause.
+		{
+			row: StatsRow{
+				Cancellations: Cancellations{Weather: 5},
+				DelayCauses: DelayCauses{
+					Weather: DelayCause{Flights: 1, Minutes: 20},
+					NAS:     DelayCause{Flights: 4, Minutes: 200},
+				},
+			},
+			expected: "weather",
+		},
+		{
+			row:      StatsRow{Diverted: 2, DelayCauses: DelayCauses{LateAircraft: DelayCause{Flights: 1, Minutes: 60}}},
+			expected: "diverted",
+		},
+		{
+			row:      StatsRow{DelayCauses: DelayCauses{LateAircraft: DelayCause{Flights: 1, Minutes: 1}}},
+			expected: "lateAircraft",
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, c.row.DominantCause())
+	}
+}
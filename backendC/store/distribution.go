@@ -0,0 +1,147 @@
+package store
+
+import "sort"
+
+// delayBucketMinutes is the width of each bucket in a delayDistribution, and
+// in the flights_day_delays table.
+const delayBucketMinutes = 5
+
+// delayDistribution is a histogram of arrival delays. Each bucket is
+// delayBucketMinutes wide and keyed by its lower bound.
+type delayDistribution struct {
+	buckets map[int]int
+	total   int
+}
+
+// Add counts flights in the bucket that contains delay.
+func (d *delayDistribution) Add(delay, flights int) {
+	if d.buckets == nil {
+		d.buckets = map[int]int{}
+	}
+
+	d.buckets[bucketFloor(delay)] += flights
+	d.total += flights
+}
+
+// Percentile estimates the delay in minutes at the pth percentile (0-100).
+// Flights are assumed to be spread evenly through each bucket.
+//
+// If the distribution is empty Percentile returns 0.
+func (d *delayDistribution) Percentile(p float64) float64 {
+	if d.total == 0 {
+		return 0
+	}
+
+	target := p / 100 * float64(d.total)
+	seen := 0.0
+
+	for _, bucket := range d.sortedBuckets() {
+		count := float64(d.buckets[bucket])
+		if seen+count >= target {
+			return float64(bucket) + (target-seen)/count*delayBucketMinutes
+		}
+		seen += count
+	}
+
+	// Only reachable when p > 100.
+	keys := d.sortedBuckets()
+	return float64(keys[len(keys)-1] + delayBucketMinutes)
+}
+
+// sortedBuckets returns the lower bounds of the non-empty buckets in
+// ascending order.
+func (d *delayDistribution) sortedBuckets() []int {
+	keys := make([]int, 0, len(d.buckets))
+	for bucket, count := range d.buckets {
+		if count > 0 {
+			keys = append(keys, bucket)
+		}
+	}
+	sort.Ints(keys)
+
+	return keys
+}
+
+// bucketFloor returns the lower bound of the bucket that contains delay.
+func bucketFloor(delay int) int {
+	floor := delay / delayBucketMinutes * delayBucketMinutes
+	if delay < 0 && delay%delayBucketMinutes != 0 {
+		floor -= delayBucketMinutes
+	}
+
+	return floor
+}
+
+// DefaultHistogramEdges are the bucket edges in minutes used for
+// StatsRow.DelayHistogram when FlightStatsOpts.HistogramEdges is empty.
+var DefaultHistogramEdges = []int{0, 15, 30, 60, 120}
+
+// DelayHistogram counts flights by arrival delay.
+type DelayHistogram struct {
+	// Buckets contains the flights that arrived, from the earliest
+	// bucket to the latest.
+	Buckets []HistogramBucket `json:"buckets"`
+
+	// Cancelled and Diverted are the flights that didn't arrive.
+	Cancelled int `json:"cancelled"`
+	Diverted  int `json:"diverted"`
+}
+
+// HistogramBucket is a range of arrival delays.
+type HistogramBucket struct {
+	// Min is the lowest delay in minutes in the bucket. It's nil for the
+	// first bucket, which has no lower bound.
+	Min *int `json:"min"`
+
+	// Max is the delay in minutes at the end of the bucket, which isn't
+	// included in it. It's nil for the last bucket, which has no upper
+	// bound.
+	Max *int `json:"max"`
+
+	// Flights is the number of flights in the bucket.
+	Flights int `json:"flights"`
+}
+
+// Histogram returns the number of flights between each of edges, which must
+// be valid according to isValidHistogramEdges. There's one more bucket than
+// there are edges. The first bucket contains every delay before edges[0]
+// and the last contains every delay from the last edge on.
+//
+// Histogram can be called on a nil distribution, which has no flights.
+func (d *delayDistribution) Histogram(edges []int) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(edges)+1)
+	for i := range edges {
+		edge := edges[i]
+		buckets[i].Max = &edge
+		buckets[i+1].Min = &edge
+	}
+
+	if d == nil {
+		return buckets
+	}
+
+	for bucket, count := range d.buckets {
+		// Edges are multiples of delayBucketMinutes, so every delay in
+		// the 5 minute bucket falls in the same histogram bucket.
+		i := sort.Search(len(edges), func(i int) bool { return edges[i] > bucket })
+		buckets[i].Flights += count
+	}
+
+	return buckets
+}
+
+// isValidHistogramEdges returns true if edges are in ascending order without
+// duplicates and every edge is a multiple of delayBucketMinutes.
+func isValidHistogramEdges(edges []int) bool {
+	for i, edge := range edges {
+		if edge%delayBucketMinutes != 0 {
+			return false
+		}
+
+		if i > 0 && edge <= edges[i-1] {
+			return false
+		}
+	}
+
+	return true
+}
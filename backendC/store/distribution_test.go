@@ -0,0 +1,112 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelayDistributionPercentile(t *testing.T) {
+	cases := []struct {
+		delays   map[int]int
+		p        float64
+		expected float64
+	}{
+		{
+			delays:   map[int]int{0: 10},
+			p:        50,
+			expected: 2.5,
+		},
+		{
+			delays:   map[int]int{-10: 5, 20: 5},
+			p:        50,
+			expected: -5,
+		},
+		{
+			delays:   map[int]int{-3: 2, 1: 6, 62: 2},
+			p:        90,
+			expected: 62.5,
+		},
+		{
+			delays:   map[int]int{},
+			p:        50,
+			expected: 0,
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		var dist delayDistribution
+		for delay, flights := range c.delays {
+			dist.Add(delay, flights)
+		}
+
+		assert.InDelta(c.expected, dist.Percentile(c.p), 0.0001)
+	}
+}
+
+func TestBucketFloor(t *testing.T) {
+	cases := map[int]int{
+		0:  0,
+		4:  0,
+		5:  5,
+		-1: -5,
+		-5: -5,
+		-6: -10,
+	}
+
+	assert := assert.New(t)
+
+	for delay, expected := range cases {
+		assert.Equal(expected, bucketFloor(delay), "delay %d", delay)
+	}
+}
+
+func TestDelayDistributionHistogram(t *testing.T) {
+	var dist delayDistribution
+	dist.Add(-12, 3)
+	dist.Add(0, 4)
+	dist.Add(14, 1)
+	dist.Add(15, 2)
+	dist.Add(59, 1)
+	dist.Add(125, 5)
+
+	assert := assert.New(t)
+
+	buckets := dist.Histogram(DefaultHistogramEdges)
+	flights := make([]int, len(buckets))
+	for i, b := range buckets {
+		flights[i] = b.Flights
+	}
+	assert.Equal([]int{3, 5, 2, 1, 0, 5}, flights)
+
+	assert.Nil(buckets[0].Min)
+	assert.Equal(0, *buckets[0].Max)
+	assert.Equal(120, *buckets[5].Min)
+	assert.Nil(buckets[5].Max)
+
+	buckets = dist.Histogram([]int{-10, 60})
+	if assert.Len(buckets, 3) {
+		assert.Equal(3, buckets[0].Flights)
+		assert.Equal(8, buckets[1].Flights)
+		assert.Equal(5, buckets[2].Flights)
+	}
+
+	var empty *delayDistribution
+	buckets = empty.Histogram(DefaultHistogramEdges)
+	assert.Len(buckets, len(DefaultHistogramEdges)+1)
+	for _, b := range buckets {
+		assert.Zero(b.Flights)
+	}
+}
+
+func TestIsValidHistogramEdges(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(isValidHistogramEdges(DefaultHistogramEdges))
+	assert.True(isValidHistogramEdges([]int{-15, 0, 45}))
+	assert.True(isValidHistogramEdges(nil))
+	assert.False(isValidHistogramEdges([]int{0, 15, 15}))
+	assert.False(isValidHistogramEdges([]int{30, 15}))
+	assert.False(isValidHistogramEdges([]int{0, 10, 12}))
+}
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"strings"
+)
+
+// FlightNumberStats contains delay information for a scheduled flight, which
+// is identified by an airline and a flight number.
+type FlightNumberStats struct {
+	// Carrier is the airline's code (e.g. "UA").
+	Carrier string `json:"carrier"`
+
+	// Airline is the airline's name.
+	Airline string `json:"airline"`
+
+	// FlightNumber is the flight number without leading zeros.
+	FlightNumber string `json:"flightNumber"`
+
+	// Legs contains each route flown under the flight number, ordered by
+	// the earliest scheduled departure time.
+	Legs []FlightLeg `json:"legs"`
+}
+
+// FlightLeg contains delay information for one route flown under a flight
+// number.
+type FlightLeg struct {
+	// Origin is the IATA code of the departure airport.
+	Origin string `json:"origin"`
+
+	// Destination is the IATA code of the arrival airport.
+	Destination string `json:"destination"`
+
+	// Summary contains the totals for the whole period.
+	Summary StatsRow `json:"summary"`
+
+	// Daily contains one row for each day the leg was scheduled.
+	Daily []StatsRow `json:"daily"`
+
+	// InboundDelays attributes the leg's late aircraft delays to the
+	// airports the aircraft arrived from, ordered from the most minutes of
+	// delay to the least.
+	InboundDelays []InboundDelay `json:"inboundDelays"`
+}
+
+// FlightNumberStats returns the history of the scheduled flight with a
+// carrier code and flight number.
+//
+// carrier is an airline code (e.g. "UA"). If it's invalid
+// ErrInvalidCarrierCode is returned. flightNumber is one to four digits, if
+// it's invalid ErrInvalidFlightNumber is returned. If there's no flight with
+// that number the result is nil.
+//
+// The TimeGroup in opts is ignored. See FlightStatsOpts for information about
+// the other options.
+func (s *Store) FlightNumberStats(ctx context.Context, carrier, flightNumber string, opts FlightStatsOpts) (*FlightNumberStats, error) {
+	carrier = strings.ToUpper(carrier)
+	if !isCarrierCode(carrier) {
+		return nil, ErrInvalidCarrierCode
+	}
+
+	if !isFlightNumber(flightNumber) {
+		return nil, ErrInvalidFlightNumber
+	}
+	flightNumber = strings.TrimLeft(flightNumber, "0")
+
+	if !isValidDateRange(opts.From, opts.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	where := []string{"carrier=?", "flight_number=?"}
+	args := []interface{}{carrier, flightNumber}
+
+	dateWhere, dateArgs := dateRangeWhere(opts.From, opts.To)
+	where = append(where, dateWhere...)
+	args = append(args, dateArgs...)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT origin, destination
+		FROM flights
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY origin, destination
+		ORDER BY MIN(scheduled_departure_time), origin, destination`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := []FlightLeg{}
+	for rows.Next() {
+		var leg FlightLeg
+		err := rows.Scan(&leg.Origin, &leg.Destination)
+		if err != nil {
+			return nil, err
+		}
+
+		legs = append(legs, leg)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(legs) == 0 {
+		return nil, nil
+	}
+
+	result := &FlightNumberStats{
+		Carrier:      carrier,
+		FlightNumber: flightNumber,
+		Legs:         legs,
+	}
+
+	// inboundDelays needs the same conditions on its "f" alias.
+	inboundWhere := make([]string, 0, len(where)+2)
+	for _, cond := range where {
+		inboundWhere = append(inboundWhere, "f."+cond)
+	}
+	inboundWhere = append(inboundWhere, "f.origin=?", "f.destination=?")
+	inboundArgs := args[:len(args):len(args)]
+
+	// The date range was already added to where, flightStats adds it
+	// again from opts.
+	where = where[:2:2]
+	args = args[:2:2]
+
+	for i := range result.Legs {
+		leg := &result.Legs[i]
+
+		legWhere := append(where, "origin=?", "destination=?")
+		legArgs := append(args, leg.Origin, leg.Destination)
+
+		opts.TimeGroup = GroupByAvailable
+		summary, err := s.flightStats(ctx, legWhere, legArgs, opts, true, false)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.TimeGroup = GroupByDay
+		daily, err := s.flightStats(ctx, legWhere, legArgs, opts, true, false)
+		if err != nil {
+			return nil, err
+		}
+
+		// The carrier is fixed, so there's one airline at most.
+		if len(summary) > 0 {
+			result.Airline = summary[0].Airline
+			leg.Summary = summary[0].Rows[0]
+		}
+
+		leg.Daily = []StatsRow{}
+		if len(daily) > 0 {
+			leg.Daily = daily[0].Rows
+		}
+
+		leg.InboundDelays, err = s.inboundDelays(ctx, inboundWhere, append(inboundArgs, leg.Origin, leg.Destination))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func isCarrierCode(code string) bool {
+	if len(code) < 2 || len(code) > 6 {
+		return false
+	}
+
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isFlightNumber(number string) bool {
+	if len(number) < 1 || len(number) > 4 {
+		return false
+	}
+
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return strings.TrimLeft(number, "0") != ""
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlightNumberStats(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	var carrier, number string
+	err := store.db.QueryRow(`
+		SELECT carrier, flight_number
+		FROM flights
+		WHERE origin='DEN' AND destination='LAS'
+		LIMIT 1`).Scan(&carrier, &number)
+	if !assert.NoError(err) {
+		return
+	}
+
+	actual, err := store.FlightNumberStats(context.Background(), carrier, number, FlightStatsOpts{})
+	if !assert.NoError(err) || !assert.NotNil(actual) {
+		return
+	}
+
+	assert.Equal(carrier, actual.Carrier)
+	assert.NotEmpty(actual.Airline)
+	assert.NotEmpty(actual.Legs)
+
+	found := false
+	for _, leg := range actual.Legs {
+		if leg.Origin == "DEN" && leg.Destination == "LAS" {
+			found = true
+		}
+
+		assert.Greater(leg.Summary.Flights, 0)
+
+		total := 0
+		for _, day := range leg.Daily {
+			assert.Equal(day.Start, day.End)
+			total += day.Flights
+		}
+		assert.Equal(leg.Summary.Flights, total)
+	}
+	assert.True(found)
+
+	actual, err = store.FlightNumberStats(context.Background(), "ZZ", "9999", FlightStatsOpts{})
+	assert.NoError(err)
+	assert.Nil(actual)
+}
+
+func TestFlightNumberStatsInvalid(t *testing.T) {
+	cases := []struct {
+		carrier, number string
+		expected        error
+	}{
+		{carrier: "U", number: "123", expected: ErrInvalidCarrierCode},
+		{carrier: "U$", number: "123", expected: ErrInvalidCarrierCode},
+		{carrier: "UA", number: "", expected: ErrInvalidFlightNumber},
+		{carrier: "UA", number: "12345", expected: ErrInvalidFlightNumber},
+		{carrier: "UA", number: "12a", expected: ErrInvalidFlightNumber},
+		{carrier: "UA", number: "0000", expected: ErrInvalidFlightNumber},
+	}
+
+	// The arguments are validated before the database is used.
+	store := &Store{}
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		_, err := store.FlightNumberStats(context.Background(), c.carrier, c.number, FlightStatsOpts{})
+		assert.Equal(c.expected, err, "%s %s", c.carrier, c.number)
+	}
+}
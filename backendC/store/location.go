@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Location is either an airport or a metro area, which groups the airports
+// that serve a city (e.g. "NYC" for JFK, LGA and EWR). Metro codes have the
+// same format as airport codes, and can be used wherever a Location is
+// accepted.
+type Location struct {
+	// Code is the airport or metro area code.
+	Code string
+
+	// Airports contains the codes of the airports in the location, sorted
+	// alphabetically. For an airport it only contains Code.
+	Airports []string
+}
+
+// IsMetro returns true if the location is a metro area.
+func (l *Location) IsMetro() bool {
+	return len(l.Airports) != 1 || l.Airports[0] != l.Code
+}
+
+// condition returns an SQL condition that matches column to the location's
+// airports, and the values for its placeholders.
+func (l *Location) condition(column string) (string, []interface{}) {
+	if len(l.Airports) == 1 {
+		return column + "=?", []interface{}{l.Airports[0]}
+	}
+
+	placeholders := make([]string, len(l.Airports))
+	args := make([]interface{}, len(l.Airports))
+	for i, code := range l.Airports {
+		placeholders[i] = "?"
+		args[i] = code
+	}
+
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")), args
+}
+
+// contains returns true if the airport code is in the location.
+func (l *Location) contains(code string) bool {
+	for _, airport := range l.Airports {
+		if airport == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isLocationCode returns true if code is a valid airport or metro area code.
+// location checks the code too, but this lets methods reject an invalid code
+// along with their other arguments, before location queries the database.
+func isLocationCode(code string) bool {
+	return isAirportCode(strings.ToUpper(code))
+}
+
+// location looks up an airport or metro area code. Codes that aren't metro
+// areas are assumed to be airports, whether or not the airport exists.
+//
+// If the code is invalid ErrInvalidAirportCode is returned.
+func (s *Store) location(ctx context.Context, code string) (*Location, error) {
+	code = strings.ToUpper(code)
+	if !isAirportCode(code) {
+		return nil, ErrInvalidAirportCode
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT airport
+		FROM metro_airports
+		WHERE metro=?
+		ORDER BY airport`,
+		code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loc := &Location{Code: code}
+	for rows.Next() {
+		var airport string
+		err := rows.Scan(&airport)
+		if err != nil {
+			return nil, err
+		}
+
+		loc.Airports = append(loc.Airports, airport)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(loc.Airports) == 0 {
+		loc.Airports = []string{code}
+	}
+
+	return loc, nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation(t *testing.T) {
+	cases := []struct {
+		code     string
+		expected *Location
+	}{
+		{
+			code:     "las",
+			expected: &Location{Code: "LAS", Airports: []string{"LAS"}},
+		},
+		{
+			code:     "NYC",
+			expected: &Location{Code: "NYC", Airports: []string{"EWR", "JFK", "LGA"}},
+		},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.location(context.Background(), c.code)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		assert.Equal(c.expected, actual)
+	}
+
+	_, err := store.location(context.Background(), "NY")
+	assert.Equal(ErrInvalidAirportCode, err)
+}
+
+func TestLocationCondition(t *testing.T) {
+	cases := []struct {
+		loc          Location
+		isMetro      bool
+		expectedCond string
+		expectedArgs []interface{}
+	}{
+		{
+			loc:          Location{Code: "LAS", Airports: []string{"LAS"}},
+			expectedCond: "origin=?",
+			expectedArgs: []interface{}{"LAS"},
+		},
+		{
+			loc:          Location{Code: "CHI", Airports: []string{"MDW", "ORD"}},
+			isMetro:      true,
+			expectedCond: "origin IN (?,?)",
+			expectedArgs: []interface{}{"MDW", "ORD"},
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		assert.Equal(c.isMetro, c.loc.IsMetro())
+
+		cond, args := c.loc.condition("origin")
+		assert.Equal(c.expectedCond, cond)
+		assert.Equal(c.expectedArgs, args)
+
+		for _, airport := range c.loc.Airports {
+			assert.True(c.loc.contains(airport))
+		}
+		assert.False(c.loc.contains("DEN"))
+	}
+}
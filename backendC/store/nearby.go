@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// earthRadiusKm is the mean radius of the Earth in kilometers.
+const earthRadiusKm = 6371.0
+
+// earthRadiusMiles is the mean radius of the Earth in statute miles.
+const earthRadiusMiles = 3958.8
+
+// centralAngleSQL returns an SQL expression for the angle in radians between
+// two points on a sphere, using the haversine formula. The arguments are SQL
+// expressions for the coordinates in degrees.
+func centralAngleSQL(lat1, lng1, lat2, lng2 string) string {
+	return fmt.Sprintf(`2 * ASIN(SQRT(
+		POW(SIN(RADIANS(%[3]s - %[1]s) / 2), 2) +
+		COS(RADIANS(%[1]s)) * COS(RADIANS(%[3]s)) *
+		POW(SIN(RADIANS(%[4]s - %[2]s) / 2), 2)
+	))`, lat1, lng1, lat2, lng2)
+}
+
+// NearbyAirport is an airport and its distance from a point.
+type NearbyAirport struct {
+	Airport *Airport `json:"airport"`
+
+	// Distance is the great-circle distance in kilometers.
+	Distance float64 `json:"distanceKm"`
+}
+
+// AirportsNear returns the active airports within radiusKm kilometers of a
+// point, ordered from the nearest to the farthest. At most limit airports are
+// returned.
+//
+// If latitude is not between -90 and 90 or longitude is not between -180 and
+// 180 ErrInvalidCoordinates is returned. If radiusKm is not positive
+// ErrInvalidRadius is returned, and if limit is not positive ErrInvalidLimit
+// is returned.
+func (s *Store) AirportsNear(ctx context.Context, latitude, longitude, radiusKm float64, limit int) ([]*NearbyAirport, error) {
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return nil, ErrInvalidCoordinates
+	}
+
+	if radiusKm <= 0 {
+		return nil, ErrInvalidRadius
+	}
+
+	if limit <= 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			code, name, city, state, lat, lng,
+			? * `+centralAngleSQL("?", "?", "lat", "lng")+` AS distance
+		FROM
+			airports
+		WHERE
+			is_active=1
+		HAVING distance <= ?
+		ORDER BY distance, code
+		LIMIT ?`,
+		earthRadiusKm, latitude, latitude, longitude, radiusKm, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*NearbyAirport{}
+	for rows.Next() {
+		var (
+			a Airport
+			n NearbyAirport
+		)
+
+		err := rows.Scan(&a.Code, &a.Name, &a.City, &a.State, &a.Latitude, &a.Longitude, &n.Distance)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Airport = &a
+		results = append(results, &n)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirportsNear(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	// Downtown Denver
+	actual, err := store.AirportsNear(context.Background(), 39.7392, -104.9903, 100, 5)
+	if !assert.NoError(err) || !assert.NotEmpty(actual) {
+		return
+	}
+
+	assert.LessOrEqual(len(actual), 5)
+	assert.Equal("DEN", actual[0].Airport.Code)
+	assert.InDelta(33, actual[0].Distance, 2)
+
+	for i, n := range actual {
+		assert.LessOrEqual(n.Distance, 100.0)
+		if i > 0 {
+			assert.LessOrEqual(actual[i-1].Distance, n.Distance)
+		}
+	}
+
+	// Middle of the Pacific
+	actual, err = store.AirportsNear(context.Background(), 0, -150, 100, 5)
+	assert.NoError(err)
+	assert.Empty(actual)
+}
+
+func TestAirportsNearInvalid(t *testing.T) {
+	cases := []struct {
+		lat, lng, radius float64
+		limit            int
+		expected         error
+	}{
+		{lat: 91, lng: 0, radius: 10, limit: 1, expected: ErrInvalidCoordinates},
+		{lat: 0, lng: -181, radius: 10, limit: 1, expected: ErrInvalidCoordinates},
+		{lat: 0, lng: 0, radius: 0, limit: 1, expected: ErrInvalidRadius},
+		{lat: 0, lng: 0, radius: 10, limit: 0, expected: ErrInvalidLimit},
+	}
+
+	// The arguments are validated before the database is used.
+	store := &Store{}
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		_, err := store.AirportsNear(context.Background(), c.lat, c.lng, c.radius, c.limit)
+		assert.Equal(c.expected, err)
+	}
+}
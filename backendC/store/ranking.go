@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RankingOpts contains flags that control how AirlineRankings operates.
+type RankingOpts struct {
+	// From is the first day to include. If From is zero there is no lower
+	// bound.
+	From time.Time
+
+	// To is the last day to include. If To is zero there is no upper
+	// bound.
+	To time.Time
+
+	// State limits the rankings to flights departing from airports in a
+	// US state or territory (e.g. "CO"). If State is empty flights from
+	// every state are included.
+	State string
+
+	// MinFlights excludes airlines with fewer flights.
+	MinFlights int
+
+	// RankBy selects the value used to order the airlines.
+	RankBy RankBy
+
+	// ReliabilityWeights are the weights for RankByReliability. The zero
+	// value means DefaultReliabilityWeights.
+	ReliabilityWeights ReliabilityWeights
+}
+
+// RankBy specifies the value used to rank airlines.
+type RankBy int
+
+const (
+	// RankByOnTime ranks airlines from the highest on-time percentage to
+	// the lowest.
+	RankByOnTime RankBy = iota
+	// RankByCancellationRate ranks airlines from the lowest cancellation
+	// rate to the highest.
+	RankByCancellationRate
+	// RankByAverageDelay ranks airlines from the lowest average arrival
+	// delay to the highest.
+	RankByAverageDelay
+	// RankByOnTimeLowerBound ranks airlines from the highest lower bound
+	// of the on-time percentage to the lowest. Unlike RankByOnTime it
+	// doesn't favor airlines with only a few flights.
+	RankByOnTimeLowerBound
+	// RankByReliability ranks airlines from the highest reliability index
+	// to the lowest. See StatsRow.Reliability.
+	RankByReliability
+)
+
+// Less returns true if a ranks above b. RankByReliability uses
+// DefaultReliabilityWeights.
+func (r RankBy) Less(a, b *StatsRow) bool {
+	return r.LessWeighted(a, b, DefaultReliabilityWeights)
+}
+
+// LessWeighted is like Less, but RankByReliability uses weights.
+func (r RankBy) LessWeighted(a, b *StatsRow, weights ReliabilityWeights) bool {
+	switch r {
+	case RankByOnTime:
+		return a.OnTime() > b.OnTime()
+	case RankByCancellationRate:
+		return a.CancellationRate() < b.CancellationRate()
+	case RankByAverageDelay:
+		return a.AverageDelay < b.AverageDelay
+	case RankByOnTimeLowerBound:
+		lowerA, _ := a.OnTimeBounds()
+		lowerB, _ := b.OnTimeBounds()
+		return lowerA > lowerB
+	case RankByReliability:
+		return a.Reliability(weights) > b.Reliability(weights)
+	}
+
+	return false
+}
+
+func (r RankBy) isValid() bool {
+	return r >= RankByOnTime && r <= RankByReliability
+}
+
+// AirlineRanking is one airline's position in the results of
+// AirlineRankings.
+type AirlineRanking struct {
+	// Rank is the position of the airline, starting at 1.
+	Rank int
+
+	// Code is the airline's IATA code (e.g. "WN").
+	Code string
+
+	// Airline is the airline's name.
+	Airline string
+
+	// Stats contains the airline's totals for the whole period.
+	// MedianDelay, P90Delay and DelayHistogram are not set.
+	Stats StatsRow
+}
+
+// AirlineRankings ranks airlines by their flights across every route.
+//
+// If opts.To is before opts.From ErrInvalidDateRange is returned. If
+// opts.State is not empty and not a valid state code ErrInvalidState is
+// returned, and if opts.MinFlights is negative ErrInvalidLimit is returned.
+//
+// If no airlines match an empty list is returned.
+func (s *Store) AirlineRankings(ctx context.Context, opts RankingOpts) ([]*AirlineRanking, error) {
+	if !isValidDateRange(opts.From, opts.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	if !opts.RankBy.isValid() {
+		return nil, fmt.Errorf("invalid RankBy value %d", opts.RankBy)
+	}
+
+	if !opts.ReliabilityWeights.IsValid() {
+		return nil, ErrInvalidReliabilityWeights
+	}
+
+	if opts.MinFlights < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	where, args := dateRangeWhere(opts.From, opts.To)
+
+	if opts.State != "" {
+		state := strings.ToUpper(opts.State)
+		if !isStateCode(state) {
+			return nil, ErrInvalidState
+		}
+
+		where = append(where, "origin IN (SELECT code FROM airports WHERE state=?)")
+		args = append(args, state)
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	cols := statsColumns(DefaultOnTimeThreshold)
+
+	query := fmt.Sprintf(`
+		SELECT
+			carriers.code,
+			carriers.name,
+			MIN(date),
+			MAX(date),
+			%s
+		FROM
+			flights_day
+			INNER JOIN carriers ON carrier=carriers.code
+		%s
+		GROUP BY carriers.code
+		HAVING SUM(total_flights) >= ?
+		ORDER BY carriers.name`,
+		statsSelect(cols, false),
+		whereClause)
+
+	rows, err := s.db.QueryContext(ctx, query, append(args, opts.MinFlights)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rankings := []*AirlineRanking{}
+	for rows.Next() {
+		var r AirlineRanking
+
+		dest := append([]interface{}{&r.Code, &r.Airline, &r.Stats.Start, &r.Stats.End}, r.Stats.scanDest(cols)...)
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Stats.DelayCauses.setShares(r.Stats.Delays)
+		rankings = append(rankings, &r)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rankings, func(i, j int) bool {
+		return opts.RankBy.LessWeighted(&rankings[i].Stats, &rankings[j].Stats, opts.ReliabilityWeights)
+	})
+
+	for i := range rankings {
+		rankings[i].Rank = i + 1
+	}
+
+	return rankings, nil
+}
+
+func isStateCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirlineRankings(t *testing.T) {
+	cases := []struct {
+		opts RankingOpts
+		less func(a, b *StatsRow) bool
+	}{
+		{
+			opts: RankingOpts{RankBy: RankByOnTime},
+			less: func(a, b *StatsRow) bool { return a.OnTime() >= b.OnTime() },
+		},
+		{
+			opts: RankingOpts{RankBy: RankByCancellationRate, State: "CO"},
+			less: func(a, b *StatsRow) bool { return a.CancellationRate() <= b.CancellationRate() },
+		},
+		{
+			opts: RankingOpts{RankBy: RankByAverageDelay, MinFlights: 10000},
+			less: func(a, b *StatsRow) bool { return a.AverageDelay <= b.AverageDelay },
+		},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.AirlineRankings(context.Background(), c.opts)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		if !assert.NotEmpty(actual) {
+			continue
+		}
+
+		for i, r := range actual {
+			assert.Equal(i+1, r.Rank)
+			assert.GreaterOrEqual(r.Stats.Flights, c.opts.MinFlights)
+			assert.NotZero(r.Stats.SchedulePadding, r.Airline)
+
+			if i > 0 {
+				assert.True(c.less(&actual[i-1].Stats, &r.Stats))
+			}
+		}
+	}
+
+	_, err := store.AirlineRankings(context.Background(), RankingOpts{State: "Colorado"})
+	assert.Equal(ErrInvalidState, err)
+}
+
+func TestAirlineRankingsInvalid(t *testing.T) {
+	cases := []struct {
+		opts     RankingOpts
+		expected error
+	}{
+		{opts: RankingOpts{State: "Colorado"}, expected: ErrInvalidState},
+		{opts: RankingOpts{MinFlights: -1}, expected: ErrInvalidLimit},
+		{opts: RankingOpts{ReliabilityWeights: ReliabilityWeights{OnTime: -1}}, expected: ErrInvalidReliabilityWeights},
+	}
+
+	// The arguments are validated before the database is used.
+	store := &Store{}
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		_, err := store.AirlineRankings(context.Background(), c.opts)
+		assert.Equal(c.expected, err)
+	}
+}
+
+func TestRankByLess(t *testing.T) {
+	few := &StatsRow{Flights: 2}
+	many := &StatsRow{Flights: 3000, Delays: 240}
+
+	assert := assert.New(t)
+	assert.True(RankByOnTime.Less(few, many))
+	assert.False(RankByOnTime.Less(many, few))
+	assert.True(RankByOnTimeLowerBound.Less(many, few))
+	assert.False(RankByOnTimeLowerBound.Less(few, many))
+
+	cancels := &StatsRow{Flights: 100, Delays: 2, Cancelled: 8}
+	delays := &StatsRow{Flights: 100, Delays: 12, AverageDelay: 10}
+	assert.True(RankByOnTime.Less(cancels, delays))
+	assert.True(RankByReliability.Less(delays, cancels))
+	assert.True(RankByReliability.LessWeighted(cancels, delays, ReliabilityWeights{OnTime: 1}))
+}
@@ -0,0 +1,93 @@
+package store
+
+import "math"
+
+// The reliability index scores each component from 0 to 100. The delay,
+// cancellation and diversion scores fall linearly from 100 to 0 as their value
+// goes from zero to these limits.
+const (
+	// maxReliabilityDelay is the average arrival delay in minutes that
+	// scores zero.
+	maxReliabilityDelay = 60.0
+
+	// maxReliabilityCancellationRate is the cancellation percentage that
+	// scores zero.
+	maxReliabilityCancellationRate = 10.0
+
+	// maxReliabilityDiversionRate is the diversion percentage that scores
+	// zero.
+	maxReliabilityDiversionRate = 2.0
+)
+
+// ReliabilityWeights sets how much each component contributes to the
+// reliability index. Only the proportions between the weights matter. The zero
+// value means DefaultReliabilityWeights.
+type ReliabilityWeights struct {
+	// OnTime weights the on-time percentage.
+	OnTime float64
+
+	// AverageDelay weights the average arrival delay.
+	AverageDelay float64
+
+	// Cancellation weights the percentage of flights cancelled.
+	Cancellation float64
+
+	// Diversion weights the percentage of flights diverted.
+	Diversion float64
+}
+
+// DefaultReliabilityWeights are the weights used when none are given.
+var DefaultReliabilityWeights = ReliabilityWeights{
+	OnTime:       0.4,
+	AverageDelay: 0.2,
+	Cancellation: 0.3,
+	Diversion:    0.1,
+}
+
+// IsValid returns false if any weight is negative.
+func (w ReliabilityWeights) IsValid() bool {
+	return w.OnTime >= 0 && w.AverageDelay >= 0 && w.Cancellation >= 0 && w.Diversion >= 0
+}
+
+// Reliability returns the reliability index of the row, which combines the
+// on-time percentage, the average arrival delay, the cancellation rate and the
+// diversion rate into one score from 0 (least reliable) to 100 (most
+// reliable).
+//
+// Each component is scored from 0 to 100:
+//
+//   - on time: the on-time percentage.
+//   - average delay: 100 for no delay (or early arrivals), falling to 0 at an
+//     average of 60 minutes.
+//   - cancellations: 100 for no cancellations, falling to 0 at 10%.
+//   - diversions: 100 for no diversions, falling to 0 at 2%.
+//
+// The index is the average of the scores, weighted by weights. If weights are
+// zero or invalid DefaultReliabilityWeights are used. A row with no flights
+// scores 0.
+func (row *StatsRow) Reliability(weights ReliabilityWeights) float64 {
+	if row.Flights <= 0 {
+		return 0
+	}
+
+	total := weights.OnTime + weights.AverageDelay + weights.Cancellation + weights.Diversion
+	if total <= 0 || !weights.IsValid() {
+		weights = DefaultReliabilityWeights
+		total = weights.OnTime + weights.AverageDelay + weights.Cancellation + weights.Diversion
+	}
+
+	diversionRate := float64(row.Diverted) / float64(row.Flights) * 100
+
+	score := weights.OnTime*row.OnTime() +
+		weights.AverageDelay*linearScore(row.AverageDelay, maxReliabilityDelay) +
+		weights.Cancellation*linearScore(row.CancellationRate(), maxReliabilityCancellationRate) +
+		weights.Diversion*linearScore(diversionRate, maxReliabilityDiversionRate)
+
+	return score / total
+}
+
+// linearScore returns 100 when value is zero or less, falling linearly to 0
+// when value is limit or more.
+func linearScore(value, limit float64) float64 {
+	return 100 * (1 - math.Min(math.Max(value, 0)/limit, 1))
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsRowReliability(t *testing.T) {
+	assert := assert.New(t)
+
+	perfect := StatsRow{Flights: 100}
+	assert.InDelta(100, perfect.Reliability(DefaultReliabilityWeights), 0.001)
+
+	// Few delays but frequent cancellations scores below a row with more
+	// delays and no cancellations.
+	cancels := StatsRow{Flights: 100, Delays: 2, Cancelled: 8, AverageDelay: 2}
+	delays := StatsRow{Flights: 100, Delays: 12, AverageDelay: 10}
+	assert.True(cancels.Reliability(DefaultReliabilityWeights) < delays.Reliability(DefaultReliabilityWeights))
+
+	// on time 90, delay 96.67, cancellations 20, diversions 100.
+	assert.InDelta(0.4*90+0.2*(100-100*2.0/60)+0.3*20+0.1*100, cancels.Reliability(DefaultReliabilityWeights), 0.001)
+
+	// Only the on-time percentage.
+	onTime := ReliabilityWeights{OnTime: 2}
+	assert.InDelta(cancels.OnTime(), cancels.Reliability(onTime), 0.001)
+
+	// Zero or negative weights use the defaults.
+	assert.Equal(cancels.Reliability(DefaultReliabilityWeights), cancels.Reliability(ReliabilityWeights{}))
+	assert.Equal(cancels.Reliability(DefaultReliabilityWeights), cancels.Reliability(ReliabilityWeights{OnTime: 1, Diversion: -1}))
+
+	// Values past the limits score zero for that component.
+	worst := StatsRow{Flights: 10, Cancelled: 5, Diverted: 5, AverageDelay: 90}
+	assert.Zero(worst.Reliability(DefaultReliabilityWeights))
+
+	assert.Zero((&StatsRow{}).Reliability(DefaultReliabilityWeights))
+}
+
+func TestReliabilityWeightsIsValid(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(DefaultReliabilityWeights.IsValid())
+	assert.True(ReliabilityWeights{}.IsValid())
+	assert.False(ReliabilityWeights{OnTime: 1, Cancellation: -0.1}.IsValid())
+}
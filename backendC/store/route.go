@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Route contains information about flights from an origin to one
+// destination.
+type Route struct {
+	// Destination is the airport at the end of the route.
+	Destination *Airport `json:"destination"`
+
+	// Flights is the number of flights on the route.
+	Flights int `json:"flights"`
+
+	// Carriers contains the names of the airlines that flew the route,
+	// sorted alphabetically.
+	Carriers []string `json:"carriers"`
+
+	// LastFlight is the day of the most recent flight.
+	LastFlight time.Time `json:"lastFlight"`
+
+	// Distance is the average distance flown in miles, as reported by
+	// BTS. When BTS didn't report it, it's the great-circle distance
+	// between the airports.
+	Distance int `json:"distanceMiles"`
+}
+
+// Routes returns the destinations with flight data from an origin airport,
+// ordered from the most flights to the least.
+//
+// origin is an IATA airport code (e.g. "LAX") or a metro area code (e.g.
+// "NYC"). If origin is invalid ErrInvalidAirportCode is returned. If there are
+// no flights from origin an empty list is returned.
+func (s *Store) Routes(ctx context.Context, origin string) ([]*Route, error) {
+	from, err := s.location(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	originCond, args := from.condition("origin")
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			airports.code,
+			airports.name,
+			airports.city,
+			airports.state,
+			airports.lat,
+			airports.lng,
+			SUM(flights_day.total_flights) AS flights,
+			GROUP_CONCAT(DISTINCT carriers.name ORDER BY carriers.name SEPARATOR '|'),
+			MAX(date),
+			IFNULL(ROUND(IFNULL(
+				AVG(distance),
+				AVG(? * `+centralAngleSQL("origins.lat", "origins.lng", "airports.lat", "airports.lng")+`)
+			)), 0)
+		FROM
+			flights_day
+			INNER JOIN airports ON destination=airports.code
+			INNER JOIN airports origins ON origin=origins.code
+			INNER JOIN carriers ON carrier=carriers.code
+		WHERE `+originCond+`
+		GROUP BY airports.code
+		ORDER BY flights DESC, airports.code`,
+		append([]interface{}{earthRadiusMiles}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routes := []*Route{}
+	for rows.Next() {
+		var (
+			a        Airport
+			r        Route
+			carriers string
+		)
+
+		err := rows.Scan(&a.Code, &a.Name, &a.City, &a.State, &a.Latitude, &a.Longitude,
+			&r.Flights, &carriers, &r.LastFlight, &r.Distance)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Destination = &a
+		r.Carriers = strings.Split(carriers, "|")
+		routes = append(routes, &r)
+	}
+
+	return routes, rows.Err()
+}
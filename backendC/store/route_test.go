@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	cases := []struct {
+		origin           string
+		expectedCodes    []string
+		expectedCarriers map[string][]string
+
+		// expectedDistances are approximate distances in miles.
+		expectedDistances map[string]int
+	}{
+		{
+			origin:        "DEN",
+			expectedCodes: []string{"LAS", "LAX", "ORD"},
+			expectedCarriers: map[string][]string{
+				"LAS": {
+					"Frontier Airlines Inc.",
+					"Southwest Airlines Co.",
+					"Spirit Air Lines",
+					"United Air Lines Inc.",
+				},
+			},
+			expectedDistances: map[string]int{
+				"LAS": 628,
+				"ORD": 888,
+			},
+		},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.Routes(context.Background(), c.origin)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		actualCodes := make([]string, len(actual))
+		for i, route := range actual {
+			actualCodes[i] = route.Destination.Code
+			assert.Greater(route.Flights, 0)
+			assert.False(route.LastFlight.IsZero())
+
+			if expected, ok := c.expectedCarriers[route.Destination.Code]; ok {
+				assert.Equal(expected, route.Carriers)
+			}
+
+			if expected, ok := c.expectedDistances[route.Destination.Code]; ok {
+				assert.InDelta(expected, route.Distance, 10, route.Destination.Code)
+			}
+		}
+
+		assert.Subset(actualCodes, c.expectedCodes)
+	}
+
+	_, err := store.Routes(context.Background(), "DENVER")
+	assert.Equal(ErrInvalidAirportCode, err)
+}
@@ -0,0 +1,331 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// airportRefreshInterval is how often the airport index checks the database
+// for changes to the active airports.
+const airportRefreshInterval = time.Minute
+
+// Relevance groups for search results, best first.
+const (
+	matchCode = iota
+	matchCodePrefix
+	matchCityPrefix
+	matchNamePrefix
+	matchSubstring
+	matchTokens
+	matchFuzzy
+	noMatch
+)
+
+// airportIndex is an in-memory search index of the active airports.
+type airportIndex struct {
+	mu          sync.RWMutex
+	airports    []*indexedAirport
+	fingerprint [3]int64
+}
+
+// indexedAirport is an airport with normalized copies of its searchable
+// fields.
+type indexedAirport struct {
+	airport Airport
+	flights int
+
+	code, city, name string
+
+	// tokens contains the words in the city and name.
+	tokens []string
+}
+
+func newIndexedAirport(a Airport, flights int) *indexedAirport {
+	ia := &indexedAirport{
+		airport: a,
+		flights: flights,
+		code:    a.Code,
+		city:    normalizeSearch(a.City),
+		name:    normalizeSearch(a.Name),
+	}
+	ia.tokens = append(strings.Fields(ia.city), strings.Fields(ia.name)...)
+
+	return ia
+}
+
+// searchResult is an airport that matched a search.
+type searchResult struct {
+	*indexedAirport
+	relevance int
+	distance  int
+}
+
+// refresh reloads the airports if the active set has changed since the last
+// call.
+func (idx *airportIndex) refresh(ctx context.Context, db *sql.DB) error {
+	var fingerprint [3]int64
+	err := db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			IFNULL(BIT_XOR(CRC32(code)), 0),
+			IFNULL(SUM(total_flights), 0)
+		FROM airports
+		WHERE is_active=1`).Scan(&fingerprint[0], &fingerprint[1], &fingerprint[2])
+	if err != nil {
+		return err
+	}
+
+	idx.mu.RLock()
+	unchanged := idx.airports != nil && fingerprint == idx.fingerprint
+	idx.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			code, name, city, state, lat, lng, total_flights
+		FROM
+			airports
+		WHERE
+			is_active=1`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	airports := []*indexedAirport{}
+	for rows.Next() {
+		var (
+			a       Airport
+			flights int
+		)
+		err := rows.Scan(&a.Code, &a.Name, &a.City, &a.State, &a.Latitude, &a.Longitude, &flights)
+		if err != nil {
+			return err
+		}
+
+		airports = append(airports, newIndexedAirport(a, flights))
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	idx.airports = airports
+	idx.fingerprint = fingerprint
+	idx.mu.Unlock()
+
+	return nil
+}
+
+// watch calls refresh every interval until ctx is done.
+func (idx *airportIndex) watch(ctx context.Context, db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := idx.refresh(ctx, db)
+			if err != nil {
+				log.Printf("unable to refresh airport index: %v", err)
+			}
+		}
+	}
+}
+
+// search returns the airports that match term, best match first. Ties are
+// broken by traffic, then by code.
+func (idx *airportIndex) search(term string) []*Airport {
+	term = normalizeSearch(term)
+	if term == "" {
+		return []*Airport{}
+	}
+
+	termCode := strings.ToUpper(term)
+	termTokens := strings.Fields(term)
+
+	idx.mu.RLock()
+	results := []searchResult{}
+	for _, ia := range idx.airports {
+		relevance, distance := ia.match(term, termCode, termTokens)
+		if relevance == noMatch {
+			continue
+		}
+
+		results = append(results, searchResult{
+			indexedAirport: ia,
+			relevance:      relevance,
+			distance:       distance,
+		})
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.relevance != b.relevance {
+			return a.relevance < b.relevance
+		}
+
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+
+		if a.flights != b.flights {
+			return a.flights > b.flights
+		}
+
+		return a.code < b.code
+	})
+
+	airports := make([]*Airport, len(results))
+	for i, r := range results {
+		// Copy the airport so callers can't modify the index.
+		a := r.airport
+		airports[i] = &a
+	}
+
+	return airports
+}
+
+// match returns the relevance group of the airport for a normalized search
+// term. termCode is the term in upper case and termTokens are its words. For
+// fuzzy matches, distance is the total edit distance of the words, otherwise
+// it's zero.
+func (ia *indexedAirport) match(term, termCode string, termTokens []string) (relevance, distance int) {
+	switch {
+	case ia.code == termCode:
+		return matchCode, 0
+	case strings.HasPrefix(ia.code, termCode):
+		return matchCodePrefix, 0
+	case strings.HasPrefix(ia.city, term):
+		return matchCityPrefix, 0
+	case strings.HasPrefix(ia.name, term):
+		return matchNamePrefix, 0
+	case strings.Contains(ia.code, termCode),
+		strings.Contains(ia.city, term),
+		strings.Contains(ia.name, term):
+		return matchSubstring, 0
+	}
+
+	// Every word in the term must match a word in the airport, in any
+	// order. The last word may be incomplete while the user is typing, so
+	// words are compared as prefixes.
+	exact := true
+	for _, tt := range termTokens {
+		best := -1
+		for _, token := range ia.tokens {
+			if strings.HasPrefix(token, tt) {
+				best = 0
+				break
+			}
+
+			d := fuzzyPrefixDistance(tt, token)
+			if d >= 0 && (best < 0 || d < best) {
+				best = d
+			}
+		}
+
+		if best < 0 {
+			return noMatch, 0
+		}
+
+		if best > 0 {
+			exact = false
+		}
+		distance += best
+	}
+
+	if exact {
+		return matchTokens, 0
+	}
+
+	return matchFuzzy, distance
+}
+
+// maxEditDistance returns the number of typos tolerated in a word.
+func maxEditDistance(word string) int {
+	switch n := len(word); {
+	case n < 4:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyPrefixDistance returns the smallest edit distance between word and a
+// prefix of token of about the same length. It returns -1 if the distance is
+// more than maxEditDistance(word).
+func fuzzyPrefixDistance(word, token string) int {
+	limit := maxEditDistance(word)
+	if limit == 0 {
+		return -1
+	}
+
+	best := -1
+	for n := len(word) - limit; n <= len(word)+limit; n++ {
+		if n < 1 || n > len(token) {
+			continue
+		}
+
+		d := editDistance(word, token[:n])
+		if d <= limit && (best < 0 || d < best) {
+			best = d
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+// minInt returns the smallest of values.
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// normalizeSearch lower cases s and replaces dashes with spaces.
+func normalizeSearch(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.Replace(s, "-", " ", -1))), " ")
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirportIndexSearch(t *testing.T) {
+	idx := &airportIndex{
+		airports: []*indexedAirport{
+			newIndexedAirport(Airport{Code: "LAS", Name: "McCarran International", City: "Las Vegas"}, 1000),
+			newIndexedAirport(Airport{Code: "VGT", Name: "North Las Vegas", City: "Las Vegas"}, 10),
+			newIndexedAirport(Airport{Code: "ORD", Name: "Chicago O'Hare International", City: "Chicago"}, 2000),
+			newIndexedAirport(Airport{Code: "MDW", Name: "Chicago Midway", City: "Chicago"}, 500),
+			newIndexedAirport(Airport{Code: "JAC", Name: "Jackson Hole", City: "Jackson"}, 50),
+			newIndexedAirport(Airport{Code: "JAX", Name: "Jacksonville International", City: "Jacksonville"}, 300),
+			newIndexedAirport(Airport{Code: "WAS", Name: "Wasilla", City: "Wasilla"}, 1),
+		},
+	}
+
+	cases := []struct {
+		term     string
+		expected []string
+	}{
+		// Exact code, then substring in the code.
+		{term: "las", expected: []string{"LAS", "VGT"}},
+		{term: "was", expected: []string{"WAS"}},
+		// City prefix, ties broken by traffic.
+		{term: "chicago", expected: []string{"ORD", "MDW"}},
+		{term: "jack", expected: []string{"JAX", "JAC"}},
+		// Name prefix before a substring.
+		{term: "north", expected: []string{"VGT"}},
+		{term: "midway", expected: []string{"MDW"}},
+		// Reordered words.
+		{term: "vegas las", expected: []string{"LAS", "VGT"}},
+		{term: "hole jackson", expected: []string{"JAC"}},
+		// Typos.
+		{term: "Las Vagas", expected: []string{"LAS", "VGT"}},
+		{term: "Chigaco", expected: []string{"ORD", "MDW"}},
+		{term: "jacksonvile", expected: []string{"JAX"}},
+		// Short words must match exactly.
+		{term: "lax", expected: []string{}},
+		{term: "denver", expected: []string{}},
+		{term: "-", expected: []string{}},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual := idx.search(c.term)
+
+		codes := make([]string, len(actual))
+		for i, a := range actual {
+			codes[i] = a.Code
+		}
+
+		assert.Equal(c.expected, codes, c.term)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"vegas", "vegas", 0},
+		{"vagas", "vegas", 1},
+		{"chigaco", "chicago", 2},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, editDistance(c.a, c.b), "%s %s", c.a, c.b)
+	}
+}
+
+func TestFuzzyPrefixDistance(t *testing.T) {
+	cases := []struct {
+		word, token string
+		expected    int
+	}{
+		{"chica", "chicago", 0},
+		{"chiga", "chicago", 1},
+		{"chigaco", "chicago", 2},
+		{"chxgxcx", "chicago", -1},
+		{"lsa", "las", -1},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, fuzzyPrefixDistance(c.word, c.token), "%s %s", c.word, c.token)
+	}
+}
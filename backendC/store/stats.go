@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 )
@@ -11,6 +12,72 @@ import (
 type FlightStatsOpts struct {
 	// TimeGroup specifies the duration to include in each aggregate bucket.
 	TimeGroup TimeGroup
+
+	// From is the first day to include. If From is zero there is no lower
+	// bound.
+	From time.Time
+
+	// To is the last day to include. If To is zero there is no upper
+	// bound.
+	To time.Time
+
+	// OnTimeThreshold is the number of minutes a flight can arrive after
+	// its scheduled time and still be on time. If OnTimeThreshold is zero
+	// DefaultOnTimeThreshold is used.
+	//
+	// Thresholds in rollupDelayColumns are read from flights_day, any other
+	// threshold reads from the much slower flights table.
+	OnTimeThreshold int
+
+	// TrendMonths is the number of trailing months used to calculate
+	// Trend.Slope. If TrendMonths is zero DefaultTrendMonths is used.
+	TrendMonths int
+
+	// HistogramEdges are the bucket edges in minutes for
+	// StatsRow.DelayHistogram. They must be in ascending order and be
+	// multiples of 5. If HistogramEdges is empty DefaultHistogramEdges is
+	// used.
+	HistogramEdges []int
+}
+
+// withDefaults returns a copy of opts with the defaults filled in for zero
+// values, or an error if opts aren't valid. See FlightStats for the errors.
+func (opts FlightStatsOpts) withDefaults() (FlightStatsOpts, error) {
+	if !isValidDateRange(opts.From, opts.To) {
+		return opts, ErrInvalidDateRange
+	}
+
+	if opts.OnTimeThreshold < 0 {
+		return opts, ErrInvalidOnTimeThreshold
+	} else if opts.OnTimeThreshold == 0 {
+		opts.OnTimeThreshold = DefaultOnTimeThreshold
+	}
+
+	if opts.TrendMonths < 0 {
+		return opts, ErrInvalidTrendMonths
+	} else if opts.TrendMonths == 0 {
+		opts.TrendMonths = DefaultTrendMonths
+	}
+
+	if len(opts.HistogramEdges) == 0 {
+		opts.HistogramEdges = DefaultHistogramEdges
+	} else if !isValidHistogramEdges(opts.HistogramEdges) {
+		return opts, ErrInvalidHistogramEdges
+	}
+
+	return opts, nil
+}
+
+// DefaultOnTimeThreshold is the number of minutes the Department of
+// Transportation allows a flight to be late and still count as on time.
+const DefaultOnTimeThreshold = 15
+
+// rollupDelayColumns maps on-time thresholds to the flights_day columns that
+// count flights delayed past them.
+var rollupDelayColumns = map[int]string{
+	15: "delayed_flights",
+	30: "delayed_flights_30",
+	60: "delayed_flights_60",
 }
 
 // TimeGroup specifies an amount of time to include in the same aggregate
@@ -24,8 +91,28 @@ const (
 	GroupByDay
 	// GroupByMonth aggregates results by month.
 	GroupByMonth
+	// GroupByWeek aggregates results by ISO week (Monday through Sunday).
+	GroupByWeek
+	// GroupByQuarter aggregates results by calendar quarter.
+	GroupByQuarter
+	// GroupByYear aggregates results by calendar year.
+	GroupByYear
+	// GroupByDayOfWeek aggregates results by the day of the week, so all
+	// Mondays are in one bucket, all Tuesdays in another and so on.
+	GroupByDayOfWeek
+	// GroupByHour aggregates results by the hour of the scheduled
+	// departure time. Since flights_day has no time of day, this reads
+	// from the flights table and is considerably slower than the other
+	// groupings.
+	GroupByHour
 )
 
+// IsCyclic returns true if the time group combines recurring periods (e.g.
+// every Monday) instead of consecutive ones.
+func (g TimeGroup) IsCyclic() bool {
+	return g == GroupByDayOfWeek || g == GroupByHour
+}
+
 // Stats is the return value of FlightStats. Each entry in the slice contains
 // data for one airline.
 type Stats []AirlineStats
@@ -35,8 +122,26 @@ type Stats []AirlineStats
 // When TimeGroup is GroupByAvailable there will only be one row. In all other
 // cases, there will be one row per time period.
 type AirlineStats struct {
+	// Code is the airline's carrier code (e.g. "WN"). Unlike the name it
+	// doesn't change between BTS releases.
+	Code    string
 	Airline string
 	Rows    []StatsRow
+
+	// Airports breaks down Rows by airport when FlightStats is called
+	// with a metro area. It's nil otherwise.
+	Airports []AirportPairStats
+
+	// Trend describes how the on-time percentage is changing across Rows.
+	// It's nil when TimeGroup is GroupByAvailable or cyclic.
+	Trend *Trend
+}
+
+// AirportPairStats contains data for the flights between two airports.
+type AirportPairStats struct {
+	Origin      string
+	Destination string
+	Rows        []StatsRow
 }
 
 // StatsRow contains delay information for a single aggregated time period.
@@ -47,65 +152,513 @@ type StatsRow struct {
 	// Start is the day of the last flight in the row.
 	End time.Time `json:"end_date"`
 
+	// Bucket identifies the row when the TimeGroup is cyclic. For
+	// GroupByDayOfWeek it is a time.Weekday, and for GroupByHour it is the
+	// hour (0-23) of the scheduled departure. It is zero otherwise.
+	Bucket int `json:"bucket"`
+
 	// Flights is the number of flights that occurred in the time period.
 	Flights int `json:"flights"`
 
-	// Delays is the number of delayed flights that occurred in the time
-	// period.
+	// Delays is the number of flights that arrived late in the time
+	// period. A flight is late when it arrives OnTimeThreshold minutes or
+	// more after its scheduled arrival. Cancelled and diverted flights are
+	// not included.
 	Delays int `json:"delays"`
+
+	// Cancelled is the number of flights that were cancelled.
+	Cancelled int `json:"cancelled"`
+
+	// Diverted is the number of flights that landed somewhere other than
+	// the destination.
+	Diverted int `json:"diverted"`
+
+	// Cancellations breaks down Cancelled by reason.
+	Cancellations Cancellations `json:"cancellations"`
+
+	// AverageDelay is the mean arrival delay in minutes of the flights
+	// that arrived. Early arrivals are negative.
+	AverageDelay float64 `json:"averageDelay"`
+
+	// MedianDelay is the median arrival delay in minutes. It's estimated
+	// from delays rounded down to delayBucketMinutes.
+	MedianDelay float64 `json:"medianDelay"`
+
+	// P90Delay is the 90th percentile arrival delay in minutes. Like
+	// MedianDelay, it's an estimate.
+	P90Delay float64 `json:"p90Delay"`
+
+	// MaxDelay is the longest arrival delay in minutes.
+	MaxDelay int `json:"maxDelay"`
+
+	// DelayCauses breaks down arrival delays by cause.
+	DelayCauses DelayCauses `json:"delayCauses"`
+
+	// DelayHistogram counts flights by arrival delay.
+	DelayHistogram DelayHistogram `json:"delayHistogram"`
+
+	// SchedulePadding is the mean number of minutes the scheduled
+	// elapsed time exceeded the actual elapsed time, for flights that
+	// arrived. A large value means the airline schedules more time than
+	// the flights need, which makes them more likely to be on time.
+	SchedulePadding float64 `json:"schedulePadding"`
+}
+
+// DelayCauses contains arrival delays by the causes BTS reports for them.
+// Airlines only report causes for flights that arrive 15 minutes or more
+// late, and a single flight may have more than one cause.
+type DelayCauses struct {
+	// Carrier covers delays within the airline's control (e.g.
+	// maintenance or crew problems).
+	Carrier DelayCause `json:"carrier"`
+
+	// Weather covers extreme weather.
+	Weather DelayCause `json:"weather"`
+
+	// NAS covers delays attributed to the National Aviation System (e.g.
+	// air traffic control and non-extreme weather).
+	NAS DelayCause `json:"nas"`
+
+	// Security covers security breaches and screening delays.
+	Security DelayCause `json:"security"`
+
+	// LateAircraft covers delays because the aircraft arrived late from
+	// its previous flight.
+	LateAircraft DelayCause `json:"lateAircraft"`
+}
+
+// DelayCause contains delay information for one cause.
+type DelayCause struct {
+	// Minutes is the total number of minutes of delay due to the cause.
+	Minutes int `json:"minutes"`
+
+	// Flights is the number of flights delayed by the cause.
+	Flights int `json:"flights"`
+
+	// Share is the fraction (0-1) of the row's delayed flights that were
+	// delayed by the cause.
+	Share float64 `json:"share"`
+}
+
+// setShares sets Share on each cause from the number of delayed flights.
+func (dc *DelayCauses) setShares(delays int) {
+	for _, cause := range []*DelayCause{&dc.Carrier, &dc.Weather, &dc.NAS, &dc.Security, &dc.LateAircraft} {
+		cause.Share = 0
+		if delays > 0 {
+			cause.Share = float64(cause.Flights) / float64(delays)
+		}
+	}
 }
 
-// OnTime returns the percentage of flights that were on time.
+// Cancellations counts cancelled flights by the reason BTS reports for them.
+type Cancellations struct {
+	// Carrier is the number of cancellations caused by the airline
+	// (code A).
+	Carrier int `json:"carrier"`
+
+	// Weather is the number of cancellations caused by the weather (code
+	// B).
+	Weather int `json:"weather"`
+
+	// NAS is the number of cancellations caused by the National Aviation
+	// System (code C).
+	NAS int `json:"nas"`
+
+	// Security is the number of cancellations caused by security (code
+	// D).
+	Security int `json:"security"`
+}
+
+// OnTime returns the percentage of flights that were on time. Cancelled and
+// diverted flights are not on time.
 func (row *StatsRow) OnTime() float64 {
 	if row.Flights <= 0 {
 		return 0
 	}
 
-	return (1.0 - float64(row.Delays)/float64(row.Flights)) * 100
+	notOnTime := row.Delays + row.Cancelled + row.Diverted
+
+	return (1.0 - float64(notOnTime)/float64(row.Flights)) * 100
+}
+
+// onTimeConfidenceZ is the z-score for the 95% confidence interval returned by
+// OnTimeBounds.
+const onTimeConfidenceZ = 1.96
+
+// OnTimeBounds returns the lower and upper bounds of the 95% confidence
+// interval for the on-time percentage, using the Wilson score interval. The
+// interval is wide when there are only a few flights, so the lower bound is a
+// fairer way to compare routes with different amounts of data.
+func (row *StatsRow) OnTimeBounds() (lower, upper float64) {
+	if row.Flights <= 0 {
+		return 0, 0
+	}
+
+	n := float64(row.Flights)
+	p := row.OnTime() / 100
+	z2 := onTimeConfidenceZ * onTimeConfidenceZ
+
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := onTimeConfidenceZ / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+
+	return math.Max(0, center-margin) * 100, math.Min(1, center+margin) * 100
+}
+
+// CancellationRate returns the percentage of flights that were cancelled.
+func (row *StatsRow) CancellationRate() float64 {
+	if row.Flights <= 0 {
+		return 0
+	}
+
+	return float64(row.Cancelled) / float64(row.Flights) * 100
+}
+
+// statsColumn is one aggregate value in a flight stats query.
+type statsColumn struct {
+	// rollup is the SQL expression when reading from flights_day.
+	rollup string
+
+	// raw is the SQL expression when reading from flights.
+	raw string
+
+	// dest returns the location to store the value in row.
+	dest func(row *StatsRow) interface{}
+}
+
+// statsColumns returns the aggregate values that make up a StatsRow, in the
+// order they are selected. threshold is the on-time threshold in minutes.
+//
+// If threshold is not in rollupDelayColumns the delays column will not have a
+// rollup expression.
+func statsColumns(threshold int) []statsColumn {
+	delays := statsColumn{
+		raw:  fmt.Sprintf("SUM(NOT cancelled AND NOT diverted AND arrival_delay >= %d)", threshold),
+		dest: func(row *StatsRow) interface{} { return &row.Delays },
+	}
+
+	if col, ok := rollupDelayColumns[threshold]; ok {
+		// The delayed_flights columns include cancelled and diverted
+		// flights.
+		delays.rollup = fmt.Sprintf("SUM(IFNULL(%s, 0) - IFNULL(cancelled_flights, 0) - IFNULL(diverted_flights, 0))", col)
+	}
+
+	cols := []statsColumn{
+		{
+			rollup: "SUM(total_flights)",
+			raw:    "COUNT(*)",
+			dest:   func(row *StatsRow) interface{} { return &row.Flights },
+		},
+		delays,
+		{
+			rollup: "SUM(IFNULL(cancelled_flights, 0))",
+			raw:    "SUM(cancelled)",
+			dest:   func(row *StatsRow) interface{} { return &row.Cancelled },
+		},
+		{
+			rollup: "SUM(IFNULL(diverted_flights, 0))",
+			raw:    "SUM(diverted)",
+			dest:   func(row *StatsRow) interface{} { return &row.Diverted },
+		},
+		{
+			rollup: "SUM(IFNULL(cancelled_carrier, 0))",
+			raw:    "SUM(cancellation_code='A')",
+			dest:   func(row *StatsRow) interface{} { return &row.Cancellations.Carrier },
+		},
+		{
+			rollup: "SUM(IFNULL(cancelled_weather, 0))",
+			raw:    "SUM(cancellation_code='B')",
+			dest:   func(row *StatsRow) interface{} { return &row.Cancellations.Weather },
+		},
+		{
+			rollup: "SUM(IFNULL(cancelled_nas, 0))",
+			raw:    "SUM(cancellation_code='C')",
+			dest:   func(row *StatsRow) interface{} { return &row.Cancellations.NAS },
+		},
+		{
+			rollup: "SUM(IFNULL(cancelled_security, 0))",
+			raw:    "SUM(cancellation_code='D')",
+			dest:   func(row *StatsRow) interface{} { return &row.Cancellations.Security },
+		},
+		{
+			rollup: "IFNULL(SUM(sum_arrival_delay) / SUM(arrived_flights), 0)",
+			raw:    "IFNULL(AVG(IF(cancelled OR diverted, NULL, arrival_delay)), 0)",
+			dest:   func(row *StatsRow) interface{} { return &row.AverageDelay },
+		},
+		{
+			rollup: "IFNULL(MAX(max_arrival_delay), 0)",
+			raw:    "IFNULL(MAX(IF(cancelled OR diverted, NULL, arrival_delay)), 0)",
+			dest:   func(row *StatsRow) interface{} { return &row.MaxDelay },
+		},
+		{
+			rollup: "IFNULL(SUM(sum_schedule_padding) / SUM(arrived_flights), 0)",
+			raw:    "IFNULL(AVG(IF(cancelled OR diverted, NULL, schedule_time - elapsed_time)), 0)",
+			dest:   func(row *StatsRow) interface{} { return &row.SchedulePadding },
+		},
+	}
+
+	causes := []struct {
+		name  string
+		cause func(row *StatsRow) *DelayCause
+	}{
+		{"carrier", func(row *StatsRow) *DelayCause { return &row.DelayCauses.Carrier }},
+		{"weather", func(row *StatsRow) *DelayCause { return &row.DelayCauses.Weather }},
+		{"nas", func(row *StatsRow) *DelayCause { return &row.DelayCauses.NAS }},
+		{"security", func(row *StatsRow) *DelayCause { return &row.DelayCauses.Security }},
+		{"late_aircraft", func(row *StatsRow) *DelayCause { return &row.DelayCauses.LateAircraft }},
+	}
+
+	for _, c := range causes {
+		cause := c.cause
+		cols = append(cols,
+			statsColumn{
+				rollup: fmt.Sprintf("SUM(IFNULL(sum_%s_delay, 0))", c.name),
+				raw:    fmt.Sprintf("SUM(IFNULL(%s_delay, 0))", c.name),
+				dest:   func(row *StatsRow) interface{} { return &cause(row).Minutes },
+			},
+			statsColumn{
+				rollup: fmt.Sprintf("SUM(IFNULL(%s_delayed_flights, 0))", c.name),
+				raw:    fmt.Sprintf("SUM(%s_delay > 0)", c.name),
+				dest:   func(row *StatsRow) interface{} { return &cause(row).Flights },
+			},
+		)
+	}
+
+	return cols
+}
+
+// onTimeColumns returns the columns from statsColumns that StatsRow.OnTime
+// needs: flights, delays, cancelled and diverted.
+func onTimeColumns(threshold int) []statsColumn {
+	return statsColumns(threshold)[:4]
+}
+
+// departureHourSQL and arrivalHourSQL are SQL expressions for the hour (0-23)
+// of a flight's scheduled departure and arrival in the flights table. Flights
+// scheduled at midnight are sometimes recorded as 24:00, so the hour wraps.
+const (
+	departureHourSQL = "HOUR(scheduled_departure_time) MOD 24"
+	arrivalHourSQL   = "HOUR(scheduled_arrival_time) MOD 24"
+)
+
+// hasRollup returns true if every column has a rollup expression.
+func hasRollup(cols []statsColumn) bool {
+	for _, col := range cols {
+		if col.rollup == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statsSelect returns the SELECT expressions for cols. If raw is true the
+// expressions read from the flights table, otherwise from flights_day.
+func statsSelect(cols []statsColumn, raw bool) string {
+	exprs := make([]string, len(cols))
+	for i, col := range cols {
+		if raw {
+			exprs[i] = col.raw
+		} else {
+			exprs[i] = col.rollup
+		}
+	}
+
+	return strings.Join(exprs, ",\n\t\t\t")
+}
+
+// scanDest returns pointers to the fields in row in the same order as cols.
+func (row *StatsRow) scanDest(cols []statsColumn) []interface{} {
+	dest := make([]interface{}, len(cols))
+	for i, col := range cols {
+		dest[i] = col.dest(row)
+	}
+
+	return dest
 }
 
 // FlightStats returns delay information about flights from an origin airport
 // to a destination.
 //
-// origin and destination are IATA airport codes (e.g. "LAX", "JFK"). If origin
-// or destination is invalid ErrInvalidAirportCode is returned.
+// origin and destination are IATA airport codes (e.g. "LAX", "JFK") or metro
+// area codes (e.g. "NYC"). If origin or destination is invalid
+// ErrInvalidAirportCode is returned. When either is a metro area the results
+// include every airport in it, and each AirlineStats is broken down by
+// airport.
+//
+// If opts.To is before opts.From ErrInvalidDateRange is returned. If
+// opts.OnTimeThreshold is negative ErrInvalidOnTimeThreshold is returned, and
+// if opts.TrendMonths is negative ErrInvalidTrendMonths is returned. If
+// opts.HistogramEdges aren't valid ErrInvalidHistogramEdges is returned.
 //
 // See FlightStatsOpts for information about opts.
 func (s *Store) FlightStats(ctx context.Context, origin, destination string, opts FlightStatsOpts) (Stats, error) {
-	origin = strings.ToUpper(origin)
-	destination = strings.ToUpper(destination)
-	if !isAirportCode(origin) || !isAirportCode(destination) {
+	from, err := s.location(ctx, origin)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	to, err := s.location(ctx, destination)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	originCond, args := from.condition("origin")
+	destCond, destArgs := to.condition("destination")
+	where := []string{originCond, destCond}
+	args = append(args, destArgs...)
+
+	stats, err := s.flightStats(ctx, where, args, opts, false, false)
+	if err != nil || !(from.IsMetro() || to.IsMetro()) {
+		return stats, err
+	}
+
+	airports, err := s.flightStats(ctx, where, args, opts, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string][]AirportPairStats, len(airports))
+	for _, airline := range airports {
+		index[airline.Code] = airline.Airports
+	}
+
+	for i := range stats {
+		stats[i].Airports = []AirportPairStats{}
+		if pairs, ok := index[stats[i].Code]; ok {
+			stats[i].Airports = pairs
+		}
+	}
+
+	return stats, nil
+}
+
+// Direction selects flights leaving or arriving at an airport.
+type Direction int
+
+const (
+	// Departures are flights leaving an airport.
+	Departures Direction = iota
+	// Arrivals are flights arriving at an airport.
+	Arrivals
+)
+
+// AirportStats returns delay information for every flight departing from or
+// arriving at an airport, depending on direction. The results are grouped by
+// airline in the same way as FlightStats.
+//
+// code is an IATA airport code. If code is invalid ErrInvalidAirportCode is
+// returned.
+//
+// See FlightStats for the other errors and FlightStatsOpts for information
+// about opts.
+func (s *Store) AirportStats(ctx context.Context, code string, direction Direction, opts FlightStatsOpts) (Stats, error) {
+	code = strings.ToUpper(code)
+	if !isAirportCode(code) {
 		return Stats{}, ErrInvalidAirportCode
 	}
 
-	groupBy := []string{"carriers.name"}
+	var where string
+	switch direction {
+	case Departures:
+		where = "origin=?"
+	case Arrivals:
+		where = "destination=?"
+	default:
+		return Stats{}, fmt.Errorf("invalid Direction value %d", direction)
+	}
+
+	return s.flightStats(ctx, []string{where}, []interface{}{code}, opts, false, false)
+}
+
+// flightStats implements FlightStats and AirportStats. where contains SQL
+// conditions for the flights to include, and args contains values for their
+// placeholders. If raw is true the flights table is always used, which is
+// necessary when where refers to columns that aren't in flights_day.
+//
+// If byAirport is true each AirlineStats is broken down by origin and
+// destination in Airports instead of Rows, and Trend isn't set.
+func (s *Store) flightStats(ctx context.Context, where []string, args []interface{}, opts FlightStatsOpts, raw, byAirport bool) (Stats, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return Stats{}, err
+	}
+
+	dateWhere, dateArgs := dateRangeWhere(opts.From, opts.To)
+	where = append(where, dateWhere...)
+	args = append(args, dateArgs...)
+
+	// groupKey identifies the time period of each row within an airline.
+	var groupKey []string
+	bucket := "0"
 
 	switch opts.TimeGroup {
 	case GroupByAvailable:
 	case GroupByDay:
-		groupBy = append(groupBy, "date")
+		groupKey = []string{"date"}
 	case GroupByMonth:
-		groupBy = append(groupBy, "YEAR(date)", "MONTH(date)")
+		groupKey = []string{"YEAR(date)", "MONTH(date)"}
+	case GroupByWeek:
+		groupKey = []string{"YEARWEEK(date, 3)"}
+	case GroupByQuarter:
+		groupKey = []string{"YEAR(date)", "QUARTER(date)"}
+	case GroupByYear:
+		groupKey = []string{"YEAR(date)"}
+	case GroupByDayOfWeek:
+		// DAYOFWEEK starts at 1 for Sunday, time.Weekday starts at 0.
+		bucket = "DAYOFWEEK(date)-1"
+		groupKey = []string{bucket}
+	case GroupByHour:
+		bucket = departureHourSQL
+		groupKey = []string{bucket}
 	default:
 		return Stats{}, fmt.Errorf("invalid TimeGroup value %d", opts.TimeGroup)
 	}
 
+	airportCols := "'' AS pair_origin, '' AS pair_destination"
+	if byAirport {
+		groupKey = append([]string{"origin", "destination"}, groupKey...)
+		airportCols = "ANY_VALUE(origin) AS pair_origin, ANY_VALUE(destination) AS pair_destination"
+	}
+
+	groupKeyExpr := "''"
+	if len(groupKey) > 0 {
+		groupKeyExpr = fmt.Sprintf("CONCAT_WS(',', %s)", strings.Join(groupKey, ", "))
+	}
+
+	cols := statsColumns(opts.OnTimeThreshold)
+
+	// flights_day is much smaller than flights, so use it unless the
+	// query needs the time of day or an unusual threshold.
+	raw = raw || opts.TimeGroup == GroupByHour || !hasRollup(cols)
+	table := "flights_day"
+	if raw {
+		table = "flights"
+	}
+
 	query := fmt.Sprintf(`
 		SELECT
 			MIN(date),
 			MAX(date),
+			ANY_VALUE(%s) AS bucket,
+			%s AS group_key,
+			%s,
+			carriers.code,
 			carriers.name,
-			SUM(total_flights),
-			SUM(IF(delayed_flights IS NULL, 0, delayed_flights)) AS delay_flights_not_null
+			%s
 		FROM
-			flights_day
+			%s
 			INNER JOIN carriers ON carrier=carriers.code
-		WHERE origin=? AND destination=?
-		GROUP BY %s
-		ORDER BY carriers.name`,
-		strings.Join(groupBy, ", "))
+		WHERE %s
+		GROUP BY carriers.code, carriers.name, group_key
+		ORDER BY carriers.name, carriers.code, pair_origin, pair_destination, bucket, MIN(date)`,
+		bucket,
+		groupKeyExpr,
+		airportCols,
+		statsSelect(cols, raw),
+		table,
+		strings.Join(where, " AND "))
 
-	rows, err := s.db.QueryContext(ctx, query, origin, destination)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -114,34 +667,212 @@ func (s *Store) FlightStats(ctx context.Context, origin, destination string, opt
 	stats := Stats{}
 	var currentAirline *AirlineStats
 
+	// pairs holds the origin and destination of each AirlineStats in
+	// stats when byAirport is true.
+	var (
+		pairs       [][2]string
+		currentPair [2]string
+	)
+
+	// rowIndex finds a row in stats by carrier code and group key.
+	type rowIndex struct{ airline, row int }
+	index := map[[2]string]rowIndex{}
+
 	for rows.Next() {
 		var (
-			airline string
-			row     StatsRow
+			code, airline, key string
+			pair               [2]string
+			row                StatsRow
 		)
 
-		err := rows.Scan(&row.Start, &row.End, &airline, &row.Flights, &row.Delays)
+		dest := append([]interface{}{&row.Start, &row.End, &row.Bucket, &key, &pair[0], &pair[1], &code, &airline}, row.scanDest(cols)...)
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, err
 		}
 
-		if currentAirline == nil {
-			currentAirline = &AirlineStats{
-				Airline: airline,
-				Rows:    []StatsRow{},
+		if currentAirline == nil || code != currentAirline.Code || pair != currentPair {
+			if currentAirline != nil {
+				stats = append(stats, *currentAirline)
+				pairs = append(pairs, currentPair)
 			}
-		} else if airline != currentAirline.Airline {
-			stats = append(stats, *currentAirline)
+
 			currentAirline = &AirlineStats{
+				Code:    code,
 				Airline: airline,
 				Rows:    []StatsRow{},
 			}
+			currentPair = pair
 		}
 
+		row.DelayCauses.setShares(row.Delays)
+
+		index[[2]string{code, key}] = rowIndex{airline: len(stats), row: len(currentAirline.Rows)}
 		currentAirline.Rows = append(currentAirline.Rows, row)
 	}
 
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if currentAirline == nil {
+		// There aren't any flights.
+		return stats, nil
+	}
 	stats = append(stats, *currentAirline)
+	pairs = append(pairs, currentPair)
+
+	// Percentiles can't be summed like the other columns, so they're
+	// calculated from a histogram of arrival delays.
+	dists, err := s.delayDistributions(ctx, raw, groupKeyExpr, where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, dist := range dists {
+		i, ok := index[key]
+		if !ok {
+			continue
+		}
+
+		row := &stats[i.airline].Rows[i.row]
+		row.MedianDelay = dist.Percentile(50)
+		row.P90Delay = dist.Percentile(90)
+	}
+
+	for key, i := range index {
+		row := &stats[i.airline].Rows[i.row]
+		row.DelayHistogram = DelayHistogram{
+			Buckets:   dists[key].Histogram(opts.HistogramEdges),
+			Cancelled: row.Cancelled,
+			Diverted:  row.Diverted,
+		}
+	}
+
+	if byAirport {
+		return groupAirports(stats, pairs), nil
+	}
+
+	for i := range stats {
+		stats[i].Trend = newTrend(stats[i].Rows, opts.TimeGroup, opts.TrendMonths)
+	}
 
 	return stats, nil
 }
+
+// groupAirports combines stats for each airline and airport pair, where pairs
+// holds the origin and destination of each AirlineStats, into one
+// AirlineStats per airline with the pairs in Airports. stats must be sorted
+// by airline.
+func groupAirports(stats Stats, pairs [][2]string) Stats {
+	grouped := Stats{}
+	for i, pairStats := range stats {
+		if len(grouped) == 0 || grouped[len(grouped)-1].Code != pairStats.Code {
+			grouped = append(grouped, AirlineStats{
+				Code:     pairStats.Code,
+				Airline:  pairStats.Airline,
+				Rows:     []StatsRow{},
+				Airports: []AirportPairStats{},
+			})
+		}
+
+		airline := &grouped[len(grouped)-1]
+		airline.Airports = append(airline.Airports, AirportPairStats{
+			Origin:      pairs[i][0],
+			Destination: pairs[i][1],
+			Rows:        pairStats.Rows,
+		})
+	}
+
+	return grouped
+}
+
+// delayDistributions returns the distribution of arrival delays for flights
+// matching the where conditions, keyed by carrier code and group key.
+//
+// If raw is true it reads from flights, otherwise from flights_day_delays.
+func (s *Store) delayDistributions(ctx context.Context, raw bool, groupKeyExpr string, where []string, args []interface{}) (map[[2]string]*delayDistribution, error) {
+	table := "flights_day_delays"
+	flights := "SUM(flights)"
+	delay := "delay_bucket"
+	if raw {
+		table = "flights"
+		flights = "COUNT(*)"
+		delay = fmt.Sprintf("FLOOR(arrival_delay/%d)*%d", delayBucketMinutes, delayBucketMinutes)
+		where = append(where[:len(where):len(where)], "NOT cancelled", "NOT diverted")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			carrier,
+			%s AS group_key,
+			%s AS delay,
+			%s
+		FROM %s
+		WHERE %s
+		GROUP BY carrier, group_key, delay`,
+		groupKeyExpr,
+		delay,
+		flights,
+		table,
+		strings.Join(where, " AND "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dists := map[[2]string]*delayDistribution{}
+
+	for rows.Next() {
+		var (
+			carrier, key   string
+			delay, flights int
+		)
+
+		err := rows.Scan(&carrier, &key, &delay, &flights)
+		if err != nil {
+			return nil, err
+		}
+
+		k := [2]string{carrier, key}
+		if dists[k] == nil {
+			dists[k] = &delayDistribution{}
+		}
+		dists[k].Add(delay, flights)
+	}
+
+	return dists, rows.Err()
+}
+
+// dateFormat is the layout MySQL uses for DATE values.
+const dateFormat = "2006-01-02"
+
+// isValidDateRange returns false if both from and to are set and to comes
+// before from.
+func isValidDateRange(from, to time.Time) bool {
+	if from.IsZero() || to.IsZero() {
+		return true
+	}
+
+	return !to.Before(from)
+}
+
+// dateRangeWhere returns SQL conditions that limit the date column to the
+// range from and to, and the values for their placeholders. Either may be zero
+// to leave that end of the range open.
+func dateRangeWhere(from, to time.Time) (where []string, args []interface{}) {
+	if !from.IsZero() {
+		where = append(where, "date>=?")
+		args = append(args, from.Format(dateFormat))
+	}
+
+	if !to.IsZero() {
+		where = append(where, "date<=?")
+		args = append(args, to.Format(dateFormat))
+	}
+
+	return where, args
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -25,6 +26,7 @@ func TestFlightStatsAvailable(t *testing.T) {
 	}
 
 	store := New()
+	defer store.Close()
 	assert := assert.New(t)
 
 	for _, c := range cases {
@@ -52,6 +54,14 @@ func TestFlightStatsAvailable(t *testing.T) {
 
 			assert.GreaterOrEqual(set[0].Flights, 0)
 			assert.GreaterOrEqual(set[0].Delays, 0)
+			assert.GreaterOrEqual(set[0].Cancelled, 0)
+			assert.GreaterOrEqual(set[0].Diverted, 0)
+
+			c := set[0].Cancellations
+			assert.Equal(set[0].Cancelled, c.Carrier+c.Weather+c.NAS+c.Security)
+
+			assert.LessOrEqual(set[0].MedianDelay, set[0].P90Delay)
+			assert.LessOrEqual(set[0].AverageDelay, float64(set[0].MaxDelay))
 		}
 	}
 }
@@ -74,6 +84,7 @@ func TestFlightStatsDaily(t *testing.T) {
 	}
 
 	store := New()
+	defer store.Close()
 	assert := assert.New(t)
 
 	for _, c := range cases {
@@ -125,6 +136,7 @@ func TestFlightStatsMonthly(t *testing.T) {
 	}
 
 	store := New()
+	defer store.Close()
 	assert := assert.New(t)
 
 	for _, c := range cases {
@@ -157,3 +169,495 @@ func TestFlightStatsMonthly(t *testing.T) {
 		}
 	}
 }
+
+func TestFlightStatsTrend(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	actual, err := store.FlightStats(
+		context.Background(),
+		"DEN", "LAS",
+		FlightStatsOpts{TimeGroup: GroupByMonth, TrendMonths: 3},
+	)
+	if assert.NoError(err) && assert.NotEmpty(actual) {
+		for _, airline := range actual {
+			assert.NotNil(airline.Trend, airline.Airline)
+		}
+	}
+
+	actual, err = store.FlightStats(context.Background(), "DEN", "LAS", FlightStatsOpts{})
+	if assert.NoError(err) && assert.NotEmpty(actual) {
+		assert.Nil(actual[0].Trend)
+	}
+
+	_, err = store.FlightStats(
+		context.Background(),
+		"DEN", "LAS",
+		FlightStatsOpts{TimeGroup: GroupByMonth, TrendMonths: -1},
+	)
+	assert.Equal(ErrInvalidTrendMonths, err)
+}
+
+func TestFlightStatsDelayHistogram(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, edges := range [][]int{nil, {-15, 0, 45}} {
+		actual, err := store.FlightStats(
+			context.Background(),
+			"DEN", "LAS",
+			FlightStatsOpts{TimeGroup: GroupByMonth, HistogramEdges: edges},
+		)
+		if !assert.NoError(err) || !assert.NotEmpty(actual) {
+			continue
+		}
+
+		expectedBuckets := len(edges) + 1
+		if edges == nil {
+			expectedBuckets = len(DefaultHistogramEdges) + 1
+		}
+
+		for _, airline := range actual {
+			for _, row := range airline.Rows {
+				hist := row.DelayHistogram
+				assert.Len(hist.Buckets, expectedBuckets)
+
+				total := hist.Cancelled + hist.Diverted
+				for _, b := range hist.Buckets {
+					total += b.Flights
+				}
+				assert.Equal(row.Flights, total, airline.Airline)
+			}
+		}
+	}
+
+	_, err := store.FlightStats(
+		context.Background(),
+		"DEN", "LAS",
+		FlightStatsOpts{HistogramEdges: []int{15, 0}},
+	)
+	assert.Equal(ErrInvalidHistogramEdges, err)
+}
+
+func TestFlightStatsDateRange(t *testing.T) {
+	cases := []struct {
+		origin, dest string
+		from, to     time.Time
+	}{
+		{
+			origin: "DEN",
+			dest:   "LAS",
+			from:   time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+			to:     time.Date(2019, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.FlightStats(
+			context.Background(),
+			c.origin, c.dest,
+			FlightStatsOpts{
+				TimeGroup: GroupByDay,
+				From:      c.from,
+				To:        c.to,
+			},
+		)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		for _, airline := range actual {
+			for _, dayStats := range airline.Rows {
+				assert.False(dayStats.Start.Before(c.from))
+				assert.False(dayStats.End.After(c.to))
+			}
+		}
+
+		_, err = store.FlightStats(
+			context.Background(),
+			c.origin, c.dest,
+			FlightStatsOpts{
+				TimeGroup: GroupByDay,
+				From:      c.to,
+				To:        c.from,
+			},
+		)
+		assert.Equal(ErrInvalidDateRange, err)
+	}
+}
+
+func TestFlightStatsCyclic(t *testing.T) {
+	cases := []struct {
+		group      TimeGroup
+		maxBuckets int
+	}{
+		{group: GroupByDayOfWeek, maxBuckets: 7},
+		{group: GroupByHour, maxBuckets: 24},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		actual, err := store.FlightStats(
+			context.Background(),
+			"DEN", "LAS",
+			FlightStatsOpts{TimeGroup: c.group},
+		)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		if !assert.NotEmpty(actual) {
+			continue
+		}
+
+		for _, airline := range actual {
+			assert.True(len(airline.Rows) <= c.maxBuckets)
+
+			for _, row := range airline.Rows {
+				assert.True(row.Bucket >= 0 && row.Bucket < c.maxBuckets)
+			}
+		}
+	}
+}
+
+func TestStatsRowOnTime(t *testing.T) {
+	cases := []struct {
+		row      StatsRow
+		expected float64
+	}{
+		{
+			row:      StatsRow{Flights: 10, Delays: 1},
+			expected: 90,
+		},
+		{
+			row:      StatsRow{Flights: 10, Delays: 1, Cancelled: 2, Diverted: 1},
+			expected: 60,
+		},
+		{
+			row:      StatsRow{},
+			expected: 0,
+		},
+	}
+
+	assert := assert.New(t)
+
+	for _, c := range cases {
+		assert.InDelta(c.expected, c.row.OnTime(), 0.0001)
+	}
+}
+
+func TestStatsRowOnTimeBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	few := StatsRow{Flights: 2}
+	many := StatsRow{Flights: 3000, Delays: 240}
+
+	for _, row := range []StatsRow{few, many} {
+		lower, upper := row.OnTimeBounds()
+		assert.True(lower <= row.OnTime())
+		assert.True(upper >= row.OnTime())
+		assert.True(lower >= 0 && upper <= 100)
+	}
+
+	// Two on-time flights don't show that an airline is more reliable than
+	// one with 92% of 3,000 flights on time.
+	fewLower, _ := few.OnTimeBounds()
+	manyLower, _ := many.OnTimeBounds()
+	assert.True(fewLower < manyLower)
+	assert.InDelta(91, manyLower, 0.1)
+
+	lower, upper := (&StatsRow{}).OnTimeBounds()
+	assert.Zero(lower)
+	assert.Zero(upper)
+}
+
+func TestFlightStatsOnTimeThreshold(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	delays := map[int]int{}
+	for _, threshold := range []int{15, 20, 60} {
+		actual, err := store.FlightStats(
+			context.Background(),
+			"DEN", "LAS",
+			FlightStatsOpts{OnTimeThreshold: threshold},
+		)
+		if !assert.NoError(err) {
+			continue
+		}
+
+		for _, airline := range actual {
+			delays[threshold] += airline.Rows[0].Delays
+		}
+	}
+
+	assert.True(delays[15] >= delays[20])
+	assert.True(delays[20] >= delays[60])
+
+	_, err := store.FlightStats(
+		context.Background(),
+		"DEN", "LAS",
+		FlightStatsOpts{OnTimeThreshold: -1},
+	)
+	assert.Equal(ErrInvalidOnTimeThreshold, err)
+}
+
+func TestFlightStatsDelayDefinition(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	// Cancelled and diverted flights, and flights arriving at or after the
+	// threshold, are delayed.
+	for _, threshold := range []int{15, 60} {
+		rows, err := store.db.Query(`
+			SELECT carrier, COUNT(*), SUM(cancelled OR diverted OR arrival_delay >= ?)
+			FROM flights
+			WHERE origin='LAS' AND destination='JFK'
+			GROUP BY carrier`, threshold)
+		if !assert.NoError(err) {
+			return
+		}
+
+		expected := map[string][2]int{}
+		for rows.Next() {
+			var (
+				carrier         string
+				flights, delays int
+			)
+			if !assert.NoError(rows.Scan(&carrier, &flights, &delays)) {
+				break
+			}
+			expected[carrier] = [2]int{flights, delays}
+		}
+		rows.Close()
+
+		actual, err := store.FlightStats(
+			context.Background(),
+			"LAS", "JFK",
+			FlightStatsOpts{OnTimeThreshold: threshold},
+		)
+		if !assert.NoError(err) {
+			return
+		}
+
+		assert.Len(actual, len(expected))
+		for _, airline := range actual {
+			row := airline.Rows[0]
+			assert.Equal(expected[airline.Code], [2]int{row.Flights, row.Delays}, airline.Code)
+		}
+	}
+}
+
+func TestDelayCausesSetShares(t *testing.T) {
+	causes := DelayCauses{
+		Carrier: DelayCause{Minutes: 100, Flights: 5},
+		Weather: DelayCause{Minutes: 30, Flights: 1},
+	}
+	causes.setShares(10)
+
+	assert := assert.New(t)
+	assert.InDelta(0.5, causes.Carrier.Share, 0.0001)
+	assert.InDelta(0.1, causes.Weather.Share, 0.0001)
+	assert.InDelta(0, causes.NAS.Share, 0.0001)
+
+	causes.setShares(0)
+	assert.InDelta(0, causes.Carrier.Share, 0.0001)
+}
+
+func TestAirportStats(t *testing.T) {
+	cases := []struct {
+		code      string
+		direction Direction
+	}{
+		{code: "DEN", direction: Departures},
+		{code: "DEN", direction: Arrivals},
+	}
+
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	routeStats, err := store.FlightStats(context.Background(), "DEN", "LAS", FlightStatsOpts{})
+	if !assert.NoError(err) {
+		return
+	}
+
+	routeFlights := 0
+	for _, airline := range routeStats {
+		routeFlights += airline.Rows[0].Flights
+	}
+
+	for _, c := range cases {
+		actual, err := store.AirportStats(context.Background(), c.code, c.direction, FlightStatsOpts{})
+		if !assert.NoError(err) {
+			continue
+		}
+
+		airportFlights := 0
+		for _, airline := range actual {
+			if assert.Len(airline.Rows, 1) {
+				airportFlights += airline.Rows[0].Flights
+			}
+		}
+
+		assert.Greater(airportFlights, routeFlights)
+	}
+
+	_, err = store.AirportStats(context.Background(), "DENVER", Departures, FlightStatsOpts{})
+	assert.Equal(ErrInvalidAirportCode, err)
+}
+
+func TestFlightStatsNoFlights(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	// There are no scheduled flights to North Las Vegas.
+	actual, err := store.FlightStats(context.Background(), "LAS", "VGT", FlightStatsOpts{})
+	if assert.NoError(err) {
+		assert.Empty(actual)
+	}
+}
+
+func TestFlightStatsMetro(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	actual, err := store.FlightStats(context.Background(), "NYC", "CHI", FlightStatsOpts{})
+	if !assert.NoError(err) || !assert.NotEmpty(actual) {
+		return
+	}
+
+	origins := map[string]bool{}
+	for _, airline := range actual {
+		if !assert.Len(airline.Rows, 1) || !assert.NotEmpty(airline.Airports) {
+			continue
+		}
+
+		total := 0
+		for i, pair := range airline.Airports {
+			if i > 0 {
+				prev := airline.Airports[i-1]
+				assert.True(prev.Origin < pair.Origin || prev.Origin == pair.Origin && prev.Destination < pair.Destination)
+			}
+
+			origins[pair.Origin] = true
+			assert.Contains([]string{"MDW", "ORD"}, pair.Destination)
+			total += pair.Rows[0].Flights
+		}
+
+		assert.Equal(airline.Rows[0].Flights, total)
+	}
+
+	assert.True(len(origins) > 1)
+
+	// Airports aren't broken down.
+	actual, err = store.FlightStats(context.Background(), "LAS", "JFK", FlightStatsOpts{})
+	if !assert.NoError(err) {
+		return
+	}
+
+	for _, airline := range actual {
+		assert.Nil(airline.Airports)
+	}
+}
+
+func TestFlightStatsOptsWithDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	opts, err := FlightStatsOpts{}.withDefaults()
+	if assert.NoError(err) {
+		assert.Equal(DefaultOnTimeThreshold, opts.OnTimeThreshold)
+		assert.Equal(DefaultTrendMonths, opts.TrendMonths)
+		assert.Equal(DefaultHistogramEdges, opts.HistogramEdges)
+	}
+
+	opts, err = FlightStatsOpts{OnTimeThreshold: 30, TrendMonths: 3, HistogramEdges: []int{0, 60}}.withDefaults()
+	if assert.NoError(err) {
+		assert.Equal(30, opts.OnTimeThreshold)
+		assert.Equal(3, opts.TrendMonths)
+		assert.Equal([]int{0, 60}, opts.HistogramEdges)
+	}
+
+	invalid := []struct {
+		opts     FlightStatsOpts
+		expected error
+	}{
+		{FlightStatsOpts{From: time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}, ErrInvalidDateRange},
+		{FlightStatsOpts{OnTimeThreshold: -1}, ErrInvalidOnTimeThreshold},
+		{FlightStatsOpts{TrendMonths: -1}, ErrInvalidTrendMonths},
+		{FlightStatsOpts{HistogramEdges: []int{15, 0}}, ErrInvalidHistogramEdges},
+	}
+
+	for _, c := range invalid {
+		_, err := c.opts.withDefaults()
+		assert.Equal(c.expected, err)
+	}
+}
+
+func TestGroupAirports(t *testing.T) {
+	rows := func(flights int) []StatsRow {
+		return []StatsRow{{Flights: flights}}
+	}
+
+	stats := Stats{
+		{Code: "AA", Airline: "American", Rows: rows(1)},
+		{Code: "AA", Airline: "American", Rows: rows(2)},
+		{Code: "UA", Airline: "United", Rows: rows(3)},
+	}
+	pairs := [][2]string{{"JFK", "MDW"}, {"JFK", "ORD"}, {"EWR", "ORD"}}
+
+	expected := Stats{
+		{
+			Code:    "AA",
+			Airline: "American",
+			Rows:    []StatsRow{},
+			Airports: []AirportPairStats{
+				{Origin: "JFK", Destination: "MDW", Rows: rows(1)},
+				{Origin: "JFK", Destination: "ORD", Rows: rows(2)},
+			},
+		},
+		{
+			Code:    "UA",
+			Airline: "United",
+			Rows:    []StatsRow{},
+			Airports: []AirportPairStats{
+				{Origin: "EWR", Destination: "ORD", Rows: rows(3)},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, groupAirports(stats, pairs))
+	assert.Equal(t, Stats{}, groupAirports(Stats{}, nil))
+}
+
+func TestDateRangeWhere(t *testing.T) {
+	assert := assert.New(t)
+
+	from := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2019, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	where, args := dateRangeWhere(from, to)
+	assert.Equal([]string{"date>=?", "date<=?"}, where)
+	assert.Equal([]interface{}{"2019-01-01", "2019-03-31"}, args)
+
+	where, args = dateRangeWhere(time.Time{}, to)
+	assert.Equal([]string{"date<=?"}, where)
+	assert.Equal([]interface{}{"2019-03-31"}, args)
+
+	where, args = dateRangeWhere(time.Time{}, time.Time{})
+	assert.Empty(where)
+	assert.Empty(args)
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -9,8 +10,8 @@ import (
 	"github.com/go-sql-driver/mysql"
 )
 
-// ErrInvalidAirportCode is returned when an airport code is invalid. To be
-// valid an airport code must contain exactly three letters.
+// ErrInvalidAirportCode is returned when an airport or metro area code is
+// invalid. To be valid a code must contain exactly three letters.
 var ErrInvalidAirportCode = errors.New("invalid airport code")
 
 // ErrInvalidTerm is returned by AirportList when the search contains invalid
@@ -18,9 +19,78 @@ var ErrInvalidAirportCode = errors.New("invalid airport code")
 // spaces.
 var ErrInvalidTerm = errors.New("invalid search term")
 
+// ErrInvalidDateRange is returned when the end of a date range comes before
+// the start.
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// ErrInvalidOnTimeThreshold is returned when an on-time threshold is
+// negative.
+var ErrInvalidOnTimeThreshold = errors.New("invalid on-time threshold")
+
+// ErrInvalidState is returned when a state code is invalid. To be valid a
+// state code must contain exactly two letters.
+var ErrInvalidState = errors.New("invalid state")
+
+// ErrInvalidCarrierCode is returned when an airline code is invalid. To be
+// valid a carrier code must contain two to six letters or numbers.
+var ErrInvalidCarrierCode = errors.New("invalid carrier code")
+
+// ErrInvalidFlightNumber is returned when a flight number is invalid. To be
+// valid a flight number must contain one to four digits.
+var ErrInvalidFlightNumber = errors.New("invalid flight number")
+
+// ErrInvalidTailNumber is returned when an aircraft's tail number is invalid.
+// To be valid a tail number must contain two to six letters or numbers.
+var ErrInvalidTailNumber = errors.New("invalid tail number")
+
+// ErrInvalidConnectionTime is returned when a minimum connection time is
+// negative.
+var ErrInvalidConnectionTime = errors.New("invalid connection time")
+
+// ErrInvalidMaxStops is returned when the maximum number of stops in an
+// itinerary is unsupported. Only one-stop itineraries are supported.
+var ErrInvalidMaxStops = errors.New("invalid max stops")
+
+// ErrInvalidCoordinates is returned when a latitude is not between -90 and 90
+// or a longitude is not between -180 and 180.
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+// ErrInvalidRadius is returned when a search radius is not positive.
+var ErrInvalidRadius = errors.New("invalid radius")
+
+// ErrInvalidLimit is returned when the maximum number of results is not
+// positive, or when a minimum number of flights is negative.
+var ErrInvalidLimit = errors.New("invalid limit")
+
+// ErrInvalidCursor is returned when a pagination cursor doesn't match any
+// result.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidTrendMonths is returned when the number of months for a trend is
+// negative.
+var ErrInvalidTrendMonths = errors.New("invalid trend months")
+
+// ErrInvalidHistogramEdges is returned when histogram bucket edges aren't in
+// ascending order or aren't multiples of 5 minutes.
+var ErrInvalidHistogramEdges = errors.New("invalid histogram edges")
+
+// ErrInvalidReliabilityWeights is returned when a reliability weight is
+// negative.
+var ErrInvalidReliabilityWeights = errors.New("invalid reliability weights")
+
+// ErrInvalidBaselineDays is returned when the number of days in a baseline is
+// negative.
+var ErrInvalidBaselineDays = errors.New("invalid baseline days")
+
 // Store contains methods for retrieving flight data from the database.
 type Store struct {
-	db *sql.DB
+	db       *sql.DB
+	airports *airportIndex
+
+	// stopWatch stops the goroutine that reloads airports, and
+	// watchDone is closed when it has returned.
+	stopWatch context.CancelFunc
+	watchDone chan struct{}
 }
 
 // New creates a new Store instance using MySQL connection information from the
@@ -31,6 +101,9 @@ type Store struct {
 //   - $MYSQL_USER - Username for MySQL
 //   - $MYSQL_PASS - Password for the MySQL user
 //
+// New also loads the active airports into memory for AirportSearch, and
+// reloads them when they change until Close is called.
+//
 // If New is unable to connect to the database it will panic.
 func New() *Store {
 	dsn := (&mysql.Config{
@@ -54,7 +127,34 @@ func New() *Store {
 		panic(fmt.Sprintf("unable to ping MySQL: %v", err))
 	}
 
-	return &Store{
-		db: db,
+	s := &Store{
+		db:       db,
+		airports: &airportIndex{},
+	}
+
+	err = s.airports.refresh(context.Background(), db)
+	if err != nil {
+		panic(fmt.Sprintf("unable to load airports: %v", err))
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWatch = cancel
+	s.watchDone = make(chan struct{})
+
+	go func() {
+		defer close(s.watchDone)
+		s.airports.watch(ctx, db, airportRefreshInterval)
+	}()
+
+	return s
+}
+
+// Close stops reloading the airports and closes the database connection. The
+// Store can't be used after it's closed.
+func (s *Store) Close() error {
+	if s.stopWatch != nil {
+		s.stopWatch()
+		<-s.watchDone
+	}
+
+	return s.db.Close()
 }
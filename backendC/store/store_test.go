@@ -3,5 +3,10 @@ package store
 import "testing"
 
 func TestConnect(t *testing.T) {
-	New()
+	store := New()
+
+	err := store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
 }
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTrendMonths is the number of trailing months used for Trend.Slope
+// when FlightStatsOpts.TrendMonths is zero.
+const DefaultTrendMonths = 6
+
+// daysPerMonth is the average length of a month, used to convert the time
+// between rows to months.
+const daysPerMonth = 365.25 / 12
+
+// Trend describes how an airline's on-time percentage is changing. Each field
+// is in percentage points, and is nil when there isn't enough data to
+// calculate it.
+//
+// The latest period is the last row, which may not be complete.
+type Trend struct {
+	// PreviousPeriod is the change in the on-time percentage from the
+	// period before the latest one.
+	PreviousPeriod *float64 `json:"previousPeriod"`
+
+	// PreviousYear is the change in the on-time percentage from the same
+	// period a year earlier.
+	PreviousYear *float64 `json:"previousYear"`
+
+	// Slope is the change in the on-time percentage per month over the
+	// trailing months, fitted with least squares.
+	Slope *float64 `json:"slope"`
+}
+
+// newTrend calculates the Trend for rows that are grouped by group and ordered
+// by date. months is the number of trailing months for the slope.
+//
+// It returns nil if group isn't a sequence of consecutive periods.
+func newTrend(rows []StatsRow, group TimeGroup, months int) *Trend {
+	if group == GroupByAvailable || group.IsCyclic() {
+		return nil
+	}
+
+	trend := &Trend{}
+	if len(rows) == 0 {
+		return trend
+	}
+
+	last := &rows[len(rows)-1]
+	period := periodStart(last.Start, group)
+
+	if len(rows) > 1 {
+		prev := &rows[len(rows)-2]
+		if periodStart(prev.Start, group).Equal(previousPeriod(period, group)) {
+			change := last.OnTime() - prev.OnTime()
+			trend.PreviousPeriod = &change
+		}
+	}
+
+	yearAgo := periodStart(period.AddDate(-1, 0, 0), group)
+	for i := len(rows) - 2; i >= 0; i-- {
+		start := periodStart(rows[i].Start, group)
+		if start.Equal(yearAgo) {
+			change := last.OnTime() - rows[i].OnTime()
+			trend.PreviousYear = &change
+		}
+
+		if !start.After(yearAgo) {
+			break
+		}
+	}
+
+	trend.Slope = onTimeSlope(rows, last.End.AddDate(0, -months, 0))
+
+	return trend
+}
+
+// onTimeSlope returns the least squares slope of the on-time percentage per
+// month for the rows that start after cutoff. It returns nil if there are
+// fewer than two rows.
+func onTimeSlope(rows []StatsRow, cutoff time.Time) *float64 {
+	var (
+		n, sumX, sumY, sumXY, sumXX float64
+		origin                      time.Time
+	)
+
+	for i := range rows {
+		row := &rows[i]
+		if !row.Start.After(cutoff) {
+			continue
+		}
+
+		if n == 0 {
+			origin = row.Start
+		}
+
+		x := row.Start.Sub(origin).Hours() / 24 / daysPerMonth
+		y := row.OnTime()
+
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || denominator == 0 {
+		return nil
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return &slope
+}
+
+// periodStart returns the first day of the period in group that contains t.
+func periodStart(t time.Time, group TimeGroup) time.Time {
+	year, month, day := t.Date()
+
+	switch group {
+	case GroupByWeek:
+		// ISO weeks start on Monday.
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case GroupByMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case GroupByQuarter:
+		return time.Date(year, (month-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+	case GroupByYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// previousPeriod returns the start of the period before the one starting at
+// start.
+func previousPeriod(start time.Time, group TimeGroup) time.Time {
+	switch group {
+	case GroupByWeek:
+		return start.AddDate(0, 0, -7)
+	case GroupByMonth:
+		return start.AddDate(0, -1, 0)
+	case GroupByQuarter:
+		return start.AddDate(0, -3, 0)
+	case GroupByYear:
+		return start.AddDate(-1, 0, 0)
+	}
+
+	return start.AddDate(0, 0, -1)
+}
+
+// FlightTrends returns the month over month trend of each airline's flights
+// from an origin to a destination, keyed by carrier code. The trends are the
+// same as AirlineStats.Trend from FlightStats with GroupByMonth, but only the
+// monthly on-time percentages are queried.
+//
+// opts.TimeGroup is ignored. See FlightStats for the arguments and errors.
+func (s *Store) FlightTrends(ctx context.Context, origin, destination string, opts FlightStatsOpts) (map[string]*Trend, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.location(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.location(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	originCond, args := from.condition("origin")
+	destCond, destArgs := to.condition("destination")
+	where := []string{originCond, destCond}
+	args = append(args, destArgs...)
+
+	dateWhere, dateArgs := dateRangeWhere(opts.From, opts.To)
+	where = append(where, dateWhere...)
+	args = append(args, dateArgs...)
+
+	cols := onTimeColumns(opts.OnTimeThreshold)
+	raw := !hasRollup(cols)
+	table := "flights_day"
+	if raw {
+		table = "flights"
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			carrier,
+			MIN(date),
+			MAX(date),
+			%s
+		FROM %s
+		WHERE %s
+		GROUP BY carrier, YEAR(date), MONTH(date)
+		ORDER BY carrier, MIN(date)`,
+		statsSelect(cols, raw),
+		table,
+		strings.Join(where, " AND ")),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	monthly := map[string][]StatsRow{}
+	for rows.Next() {
+		var (
+			carrier string
+			row     StatsRow
+		)
+
+		dest := append([]interface{}{&carrier, &row.Start, &row.End}, row.scanDest(cols)...)
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		monthly[carrier] = append(monthly[carrier], row)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make(map[string]*Trend, len(monthly))
+	for carrier, carrierRows := range monthly {
+		trends[carrier] = newTrend(carrierRows, GroupByMonth, opts.TrendMonths)
+	}
+
+	return trends, nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrend(t *testing.T) {
+	month := func(year int, month time.Month, delays int) StatsRow {
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return StatsRow{
+			Start:   start,
+			End:     start.AddDate(0, 1, -1),
+			Flights: 100,
+			Delays:  delays,
+		}
+	}
+
+	assert := assert.New(t)
+
+	rows := []StatsRow{
+		month(2018, time.March, 30),
+		month(2019, time.January, 20),
+		month(2019, time.February, 15),
+		month(2019, time.March, 10),
+	}
+
+	trend := newTrend(rows, GroupByMonth, 6)
+	if assert.NotNil(trend) {
+		if assert.NotNil(trend.PreviousPeriod) {
+			assert.InDelta(5, *trend.PreviousPeriod, 0.0001)
+		}
+		if assert.NotNil(trend.PreviousYear) {
+			assert.InDelta(20, *trend.PreviousYear, 0.0001)
+		}
+		if assert.NotNil(trend.Slope) {
+			// March 2018 is outside the trailing 6 months.
+			assert.InDelta(5, *trend.Slope, 0.3)
+		}
+	}
+
+	// A gap means there's no previous period.
+	trend = newTrend([]StatsRow{rows[0], rows[3]}, GroupByMonth, 6)
+	if assert.NotNil(trend) {
+		assert.Nil(trend.PreviousPeriod)
+		assert.NotNil(trend.PreviousYear)
+		assert.Nil(trend.Slope)
+	}
+
+	trend = newTrend(nil, GroupByMonth, 6)
+	if assert.NotNil(trend) {
+		assert.Nil(trend.PreviousPeriod)
+		assert.Nil(trend.PreviousYear)
+		assert.Nil(trend.Slope)
+	}
+
+	assert.Nil(newTrend(rows, GroupByAvailable, 6))
+	assert.Nil(newTrend(rows, GroupByDayOfWeek, 6))
+}
+
+func TestPeriodStart(t *testing.T) {
+	// Thursday, August 15 2019.
+	date := time.Date(2019, time.August, 15, 0, 0, 0, 0, time.UTC)
+
+	cases := map[TimeGroup]time.Time{
+		GroupByDay:     date,
+		GroupByWeek:    time.Date(2019, time.August, 12, 0, 0, 0, 0, time.UTC),
+		GroupByMonth:   time.Date(2019, time.August, 1, 0, 0, 0, 0, time.UTC),
+		GroupByQuarter: time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC),
+		GroupByYear:    time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert := assert.New(t)
+	for group, expected := range cases {
+		assert.Equal(expected, periodStart(date, group), "group %d", group)
+	}
+
+	// Sunday is the end of an ISO week.
+	sunday := time.Date(2019, time.August, 18, 0, 0, 0, 0, time.UTC)
+	assert.Equal(cases[GroupByWeek], periodStart(sunday, GroupByWeek))
+}
+
+func TestFlightTrends(t *testing.T) {
+	store := New()
+	defer store.Close()
+	assert := assert.New(t)
+
+	opts := FlightStatsOpts{TimeGroup: GroupByMonth}
+	stats, err := store.FlightStats(context.Background(), "LAS", "JFK", opts)
+	if !assert.NoError(err) {
+		return
+	}
+
+	trends, err := store.FlightTrends(context.Background(), "LAS", "JFK", opts)
+	if !assert.NoError(err) {
+		return
+	}
+
+	// The trends match the ones from the full monthly stats.
+	assert.Len(trends, len(stats))
+	for _, airline := range stats {
+		assert.Equal(airline.Trend, trends[airline.Code], airline.Code)
+	}
+}
+
+func TestFlightTrendsInvalid(t *testing.T) {
+	store := &Store{}
+	assert := assert.New(t)
+
+	_, err := store.FlightTrends(context.Background(), "LAS", "JFK", FlightStatsOpts{TrendMonths: -1})
+	assert.Equal(ErrInvalidTrendMonths, err)
+
+	_, err = store.FlightTrends(context.Background(), "LAS", "JFK", FlightStatsOpts{OnTimeThreshold: -1})
+	assert.Equal(ErrInvalidOnTimeThreshold, err)
+
+	_, err = store.FlightTrends(context.Background(), "LAS", "JFK", FlightStatsOpts{HistogramEdges: []int{15, 0}})
+	assert.Equal(ErrInvalidHistogramEdges, err)
+}
@@ -21,6 +21,7 @@ type record struct {
 	DepDelay             int
 	Origin               string
 	Dest                 string
+	Distance             int
 	TaxiIn               int
 	TaxiOut              int
 	WheelsOff            int
@@ -75,6 +76,8 @@ func newRecord(row, header []string) record {
 			r.Origin = row[i]
 		case "Dest":
 			r.Dest = row[i]
+		case "Distance":
+			r.Distance = readDecimalInt(row[i])
 		case "TaxiIn":
 			r.TaxiIn = readDecimalInt(row[i])
 		case "TaxiOut":
@@ -24,6 +24,7 @@ var columns = []string{
 	"tail_number",
 	"origin",
 	"destination",
+	"distance",
 	"cancelled",
 	"cancellation_code",
 	"diverted",
@@ -138,25 +139,26 @@ func writeSingleRecord(csv *csv.Writer, record *record) error {
 	return csv.Write([]string{
 		"0",
 		record.Date,                               // date
-		strconv.Itoa(record.DepTime),              // departure_time
-		strconv.Itoa(record.ScheduledDepTime),     // scheduled_departure_time
-		strconv.Itoa(record.ArrTime),              // arrival_time
-		strconv.Itoa(record.ScheduledArrTime),     // scheduled_arrival_time
+		formatTime(record.DepTime),                // departure_time
+		formatTime(record.ScheduledDepTime),       // scheduled_departure_time
+		formatTime(record.ArrTime),                // arrival_time
+		formatTime(record.ScheduledArrTime),       // scheduled_arrival_time
 		record.Airline,                            // carrier
 		record.FlightNum,                          // flight_number
 		record.TailNum,                            // tail_number
 		record.Origin,                             // origin
 		record.Dest,                               // destination
-		strconv.FormatBool(record.Cancelled),      // cancelled
+		formatDistance(record.Distance),           // distance
+		formatBool(record.Cancelled),              // cancelled
 		record.CancellationCode,                   // cancellation_code
-		strconv.FormatBool(record.Diverted),       // diverted
+		formatBool(record.Diverted),               // diverted
 		strconv.Itoa(record.ActualElapsedTime),    // elapsed_time
 		strconv.Itoa(record.ScheduledElapsedTime), // schedule_time
 		strconv.Itoa(record.AirTime),              // air_time
 		strconv.Itoa(record.TaxiIn),               // taxi_in_time
 		strconv.Itoa(record.TaxiOut),              // taxi_out_time
-		strconv.Itoa(record.WheelsOff),            // wheels_off_time
-		strconv.Itoa(record.WheelsOn),             // wheels_on_time
+		formatTime(record.WheelsOff),              // wheels_off_time
+		formatTime(record.WheelsOn),               // wheels_on_time
 		strconv.Itoa(record.ArrDelay),             // arrival_delay
 		strconv.Itoa(record.DepDelay),             // departure_delay
 		strconv.Itoa(record.CarrierDelay),         // carrier_delay
@@ -166,3 +168,27 @@ func writeSingleRecord(csv *csv.Writer, record *record) error {
 		strconv.Itoa(record.LateAircraftDelay),    // late_aircraft_delay
 	})
 }
+
+// formatTime converts a time in the BTS "hhmm" format (e.g. 1405) to
+// "hh:mm:00". MySQL reads a bare "1405" as 00:14:05.
+func formatTime(hhmm int) string {
+	return fmt.Sprintf("%02d:%02d:00", hhmm/100, hhmm%100)
+}
+
+// formatDistance converts a distance in miles to a string. Zero means the
+// distance is missing, which is written as NULL.
+func formatDistance(miles int) string {
+	if miles <= 0 {
+		return `\N`
+	}
+	return strconv.Itoa(miles)
+}
+
+// formatBool converts b to "1" or "0". MySQL stores "true" in a BOOLEAN column
+// as 0.
+func formatBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
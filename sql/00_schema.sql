@@ -13,6 +13,9 @@ CREATE TABLE airports (
     lat DECIMAL(10, 8),
     lng DECIMAL(11, 8),
     is_active BOOLEAN,
+    -- Departures and arrivals in flights_day, set by
+    -- sql/updates/rollup.sql.
+    total_flights INT NOT NULL DEFAULT 0,
 
     PRIMARY KEY (code)
 );
@@ -31,6 +34,8 @@ CREATE TABLE flights (
 
     origin CHAR(3),
     destination CHAR(3),
+    -- Great-circle distance in miles, as reported by BTS.
+    distance SMALLINT,
 
     cancelled BOOLEAN,
     cancellation_code CHAR(1),
@@ -61,7 +66,8 @@ CREATE TABLE flights (
     INDEX carrier_idx (carrier),
     INDEX origin_idx (origin),
     INDEX destination_idx (destination),
-    INDEX date_idx (date)
+    INDEX date_idx (date),
+    INDEX tail_number_idx (tail_number, date)
 );
 
 CREATE TABLE flights_day (
@@ -69,9 +75,41 @@ CREATE TABLE flights_day (
     carrier VARCHAR(6),
     origin CHAR(3),
     destination CHAR(3),
+    distance SMALLINT,
 
     total_flights SMALLINT,
+    -- Flights that were not on time, including cancelled and diverted
+    -- flights. A flight is late when it arrives 15 minutes or more after
+    -- the scheduled time.
     delayed_flights SMALLINT,
+    -- The same as delayed_flights, but with 30 and 60 minute thresholds.
+    delayed_flights_30 SMALLINT,
+    delayed_flights_60 SMALLINT,
+    cancelled_flights SMALLINT,
+    diverted_flights SMALLINT,
+    -- Cancellations by cancellation_code (A, B, C and D).
+    cancelled_carrier SMALLINT,
+    cancelled_weather SMALLINT,
+    cancelled_nas SMALLINT,
+    cancelled_security SMALLINT,
+    -- Arrival delay totals for flights that weren't cancelled or diverted.
+    arrived_flights SMALLINT,
+    sum_arrival_delay INT,
+    max_arrival_delay SMALLINT,
+    -- Delay minutes and delayed flights for each cause.
+    sum_carrier_delay INT,
+    carrier_delayed_flights SMALLINT,
+    sum_weather_delay INT,
+    weather_delayed_flights SMALLINT,
+    sum_nas_delay INT,
+    nas_delayed_flights SMALLINT,
+    sum_security_delay INT,
+    security_delayed_flights SMALLINT,
+    sum_late_aircraft_delay INT,
+    late_aircraft_delayed_flights SMALLINT,
+    -- Total minutes the scheduled elapsed time exceeded the actual elapsed
+    -- time, for flights that weren't cancelled or diverted.
+    sum_schedule_padding INT,
 
     PRIMARY KEY (date, carrier, origin, destination),
     FOREIGN KEY (carrier) REFERENCES carriers(code),
@@ -83,3 +121,42 @@ CREATE TABLE flights_day (
     INDEX destination_idx (destination),
     INDEX date_idx (date)
 );
+
+-- A histogram of arrival delays for each row in flights_day. delay_bucket is
+-- arrival_delay rounded down to a multiple of 5 minutes. Cancelled and diverted
+-- flights are excluded.
+CREATE TABLE flights_day_delays (
+    date DATE NOT NULL,
+    carrier VARCHAR(6),
+    origin CHAR(3),
+    destination CHAR(3),
+    delay_bucket SMALLINT NOT NULL,
+
+    flights SMALLINT,
+
+    PRIMARY KEY (date, carrier, origin, destination, delay_bucket),
+    FOREIGN KEY (carrier) REFERENCES carriers(code),
+    FOREIGN KEY (origin) REFERENCES airports(code),
+    FOREIGN KEY (destination) REFERENCES airports(code),
+
+    INDEX origin_idx (origin),
+    INDEX destination_idx (destination)
+);
+
+-- Metropolitan areas served by more than one airport. A metro code can be used
+-- anywhere an airport code can, so it must not be the same as an airport code.
+CREATE TABLE metro_areas (
+    code CHAR(3),
+    name VARCHAR(64),
+
+    PRIMARY KEY (code)
+);
+
+CREATE TABLE metro_airports (
+    metro CHAR(3),
+    airport CHAR(3),
+
+    PRIMARY KEY (metro, airport),
+    FOREIGN KEY (metro) REFERENCES metro_areas(code),
+    FOREIGN KEY (airport) REFERENCES airports(code)
+);
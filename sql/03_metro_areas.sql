@@ -0,0 +1,31 @@
+-- Metropolitan areas use IATA metropolitan area codes where one exists.
+INSERT INTO metro_areas (code, name) VALUES
+    ('CHI', 'Chicago'),
+    ('NYC', 'New York City'),
+    ('QDF', 'Dallas-Fort Worth'),
+    ('QHO', 'Houston'),
+    ('QLA', 'Los Angeles'),
+    ('QSF', 'San Francisco Bay Area'),
+    ('WAS', 'Washington, D.C.');
+
+INSERT INTO metro_airports (metro, airport) VALUES
+    ('CHI', 'MDW'),
+    ('CHI', 'ORD'),
+    ('NYC', 'EWR'),
+    ('NYC', 'JFK'),
+    ('NYC', 'LGA'),
+    ('QDF', 'DAL'),
+    ('QDF', 'DFW'),
+    ('QHO', 'HOU'),
+    ('QHO', 'IAH'),
+    ('QLA', 'BUR'),
+    ('QLA', 'LAX'),
+    ('QLA', 'LGB'),
+    ('QLA', 'ONT'),
+    ('QLA', 'SNA'),
+    ('QSF', 'OAK'),
+    ('QSF', 'SFO'),
+    ('QSF', 'SJC'),
+    ('WAS', 'BWI'),
+    ('WAS', 'DCA'),
+    ('WAS', 'IAD');
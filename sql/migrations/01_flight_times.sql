@@ -0,0 +1,22 @@
+-- Older versions of the loader wrote times as bare "hhmm" numbers, which MySQL
+-- stores as 00:hh:mm. This moves them to hh:mm:00.
+--
+-- The loader never writes seconds, so only days loaded in the bare "hhmm" form
+-- have scheduled times with seconds. Just those days are updated, which makes
+-- it safe to run this more than once or on a database that doesn't need it.
+UPDATE flights
+    INNER JOIN (
+        SELECT DISTINCT date
+        FROM flights
+        WHERE SECOND(scheduled_departure_time) <> 0 OR SECOND(scheduled_arrival_time) <> 0
+    ) broken_days USING (date)
+SET
+    departure_time = SEC_TO_TIME(MINUTE(departure_time)*3600 + SECOND(departure_time)*60),
+    scheduled_departure_time = SEC_TO_TIME(MINUTE(scheduled_departure_time)*3600 + SECOND(scheduled_departure_time)*60),
+    arrival_time = SEC_TO_TIME(MINUTE(arrival_time)*3600 + SECOND(arrival_time)*60),
+    scheduled_arrival_time = SEC_TO_TIME(MINUTE(scheduled_arrival_time)*3600 + SECOND(scheduled_arrival_time)*60),
+    wheels_off_time = SEC_TO_TIME(MINUTE(wheels_off_time)*3600 + SECOND(wheels_off_time)*60),
+    wheels_on_time = SEC_TO_TIME(MINUTE(wheels_on_time)*3600 + SECOND(wheels_on_time)*60)
+WHERE
+    HOUR(scheduled_departure_time) = 0 AND
+    HOUR(scheduled_arrival_time) = 0;
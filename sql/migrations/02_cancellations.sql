@@ -0,0 +1,17 @@
+-- Adds cancellation and diversion counts to flights_day.
+--
+-- Older versions of the loader wrote "true" and "false" to the cancelled and
+-- diverted columns, which MySQL stores as 0. cancelled can be recovered from
+-- cancellation_code, but diverted can only be fixed by reloading the data.
+UPDATE flights SET cancelled = cancellation_code IN ('A', 'B', 'C', 'D');
+
+ALTER TABLE flights_day
+    ADD COLUMN cancelled_flights SMALLINT AFTER delayed_flights,
+    ADD COLUMN diverted_flights SMALLINT AFTER cancelled_flights,
+    ADD COLUMN cancelled_carrier SMALLINT AFTER diverted_flights,
+    ADD COLUMN cancelled_weather SMALLINT AFTER cancelled_carrier,
+    ADD COLUMN cancelled_nas SMALLINT AFTER cancelled_weather,
+    ADD COLUMN cancelled_security SMALLINT AFTER cancelled_nas;
+
+-- Rebuild flights_day by running sql/updates/rollup.sql after this.
+TRUNCATE flights_day;
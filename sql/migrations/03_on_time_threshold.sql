@@ -0,0 +1,8 @@
+-- Counts late flights with arrival_delay instead of comparing scheduled and
+-- actual times, and adds columns for 30 and 60 minute thresholds.
+ALTER TABLE flights_day
+    ADD COLUMN delayed_flights_30 SMALLINT AFTER delayed_flights,
+    ADD COLUMN delayed_flights_60 SMALLINT AFTER delayed_flights_30;
+
+-- Rebuild flights_day by running sql/updates/rollup.sql after this.
+TRUNCATE flights_day;
@@ -0,0 +1,27 @@
+-- Adds arrival delay totals to flights_day and creates flights_day_delays.
+ALTER TABLE flights_day
+    ADD COLUMN arrived_flights SMALLINT AFTER cancelled_security,
+    ADD COLUMN sum_arrival_delay INT AFTER arrived_flights,
+    ADD COLUMN max_arrival_delay SMALLINT AFTER sum_arrival_delay;
+
+CREATE TABLE flights_day_delays (
+    date DATE NOT NULL,
+    carrier VARCHAR(6),
+    origin CHAR(3),
+    destination CHAR(3),
+    delay_bucket SMALLINT NOT NULL,
+
+    flights SMALLINT,
+
+    PRIMARY KEY (date, carrier, origin, destination, delay_bucket),
+    FOREIGN KEY (carrier) REFERENCES carriers(code),
+    FOREIGN KEY (origin) REFERENCES airports(code),
+    FOREIGN KEY (destination) REFERENCES airports(code),
+
+    INDEX origin_idx (origin),
+    INDEX destination_idx (destination)
+);
+
+-- Rebuild flights_day and flights_day_delays by running
+-- sql/updates/rollup.sql after this.
+TRUNCATE flights_day;
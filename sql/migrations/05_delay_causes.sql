@@ -0,0 +1,17 @@
+-- Adds delay cause totals to flights_day.
+ALTER TABLE flights_day
+    ADD COLUMN sum_carrier_delay INT AFTER max_arrival_delay,
+    ADD COLUMN carrier_delayed_flights SMALLINT AFTER sum_carrier_delay,
+    ADD COLUMN sum_weather_delay INT AFTER carrier_delayed_flights,
+    ADD COLUMN weather_delayed_flights SMALLINT AFTER sum_weather_delay,
+    ADD COLUMN sum_nas_delay INT AFTER weather_delayed_flights,
+    ADD COLUMN nas_delayed_flights SMALLINT AFTER sum_nas_delay,
+    ADD COLUMN sum_security_delay INT AFTER nas_delayed_flights,
+    ADD COLUMN security_delayed_flights SMALLINT AFTER sum_security_delay,
+    ADD COLUMN sum_late_aircraft_delay INT AFTER security_delayed_flights,
+    ADD COLUMN late_aircraft_delayed_flights SMALLINT AFTER sum_late_aircraft_delay;
+
+-- Rebuild flights_day and flights_day_delays by running
+-- sql/updates/rollup.sql after this.
+TRUNCATE flights_day;
+TRUNCATE flights_day_delays;
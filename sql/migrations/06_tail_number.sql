@@ -0,0 +1,3 @@
+-- Indexes flights by aircraft, which is needed to find the flight that
+-- brought an aircraft to the airport.
+CREATE INDEX tail_number_idx ON flights (tail_number, date);
@@ -0,0 +1,17 @@
+-- Adds metropolitan areas. Run sql/03_metro_areas.sql after this to populate
+-- them.
+CREATE TABLE metro_areas (
+    code CHAR(3),
+    name VARCHAR(64),
+
+    PRIMARY KEY (code)
+);
+
+CREATE TABLE metro_airports (
+    metro CHAR(3),
+    airport CHAR(3),
+
+    PRIMARY KEY (metro, airport),
+    FOREIGN KEY (metro) REFERENCES metro_areas(code),
+    FOREIGN KEY (airport) REFERENCES airports(code)
+);
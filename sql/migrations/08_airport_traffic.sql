@@ -0,0 +1,8 @@
+-- Adds airport traffic, which ranks airport search results. Run this after
+-- flights_day is up to date.
+ALTER TABLE airports
+    ADD COLUMN total_flights INT NOT NULL DEFAULT 0 AFTER is_active;
+
+UPDATE airports SET total_flights=
+    IFNULL((SELECT SUM(total_flights) FROM flights_day WHERE origin=airports.code), 0) +
+    IFNULL((SELECT SUM(total_flights) FROM flights_day WHERE destination=airports.code), 0);
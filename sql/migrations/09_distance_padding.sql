@@ -0,0 +1,16 @@
+-- Adds route distance to flights and flights_day, and schedule padding totals
+-- to flights_day.
+--
+-- Flights loaded before this have no distance. Reload them to fill it in, the
+-- backend falls back to the distance between the airports' coordinates.
+ALTER TABLE flights
+    ADD COLUMN distance SMALLINT AFTER destination;
+
+ALTER TABLE flights_day
+    ADD COLUMN distance SMALLINT AFTER destination,
+    ADD COLUMN sum_schedule_padding INT AFTER late_aircraft_delayed_flights;
+
+-- Rebuild flights_day and flights_day_delays by running
+-- sql/updates/rollup.sql after this.
+TRUNCATE flights_day;
+TRUNCATE flights_day_delays;
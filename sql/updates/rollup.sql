@@ -1,8 +1,47 @@
-INSERT INTO flights_day (date, carrier, origin, destination, total_flights, delayed_flights)
-    SELECT totals.date, totals.carrier, totals.origin, totals.destination, totals.count, delays.count
-    FROM (
-        SELECT date, carrier, origin, destination, count(*) AS count FROM flights GROUP BY date, carrier, origin, destination
-    ) AS totals
-    LEFT OUTER JOIN (
-        SELECT date, carrier, origin, destination, count(*) AS count FROM flights WHERE scheduled_departure_time <= departure_time AND scheduled_arrival_time <= arrival_time GROUP BY date, carrier, origin, destination
-    ) AS delays ON totals.date=delays.date AND totals.carrier=delays.carrier AND totals.origin=delays.origin AND totals.destination=delays.destination;
+INSERT INTO flights_day (
+    date, carrier, origin, destination, distance,
+    total_flights, delayed_flights, delayed_flights_30, delayed_flights_60,
+    cancelled_flights, diverted_flights,
+    cancelled_carrier, cancelled_weather, cancelled_nas, cancelled_security,
+    arrived_flights, sum_arrival_delay, max_arrival_delay,
+    sum_carrier_delay, carrier_delayed_flights,
+    sum_weather_delay, weather_delayed_flights,
+    sum_nas_delay, nas_delayed_flights,
+    sum_security_delay, security_delayed_flights,
+    sum_late_aircraft_delay, late_aircraft_delayed_flights,
+    sum_schedule_padding
+)
+    SELECT
+        date, carrier, origin, destination, MAX(distance),
+        COUNT(*),
+        SUM(cancelled OR diverted OR arrival_delay >= 15),
+        SUM(cancelled OR diverted OR arrival_delay >= 30),
+        SUM(cancelled OR diverted OR arrival_delay >= 60),
+        SUM(cancelled),
+        SUM(diverted),
+        SUM(cancellation_code='A'),
+        SUM(cancellation_code='B'),
+        SUM(cancellation_code='C'),
+        SUM(cancellation_code='D'),
+        SUM(NOT cancelled AND NOT diverted),
+        SUM(IF(cancelled OR diverted, 0, arrival_delay)),
+        MAX(IF(cancelled OR diverted, NULL, arrival_delay)),
+        SUM(carrier_delay), SUM(carrier_delay > 0),
+        SUM(weather_delay), SUM(weather_delay > 0),
+        SUM(nas_delay), SUM(nas_delay > 0),
+        SUM(security_delay), SUM(security_delay > 0),
+        SUM(late_aircraft_delay), SUM(late_aircraft_delay > 0),
+        SUM(IF(cancelled OR diverted, 0, schedule_time - elapsed_time))
+    FROM flights
+    GROUP BY date, carrier, origin, destination;
+
+INSERT INTO flights_day_delays (date, carrier, origin, destination, delay_bucket, flights)
+    SELECT date, carrier, origin, destination, FLOOR(arrival_delay/5)*5 AS delay_bucket, COUNT(*)
+    FROM flights
+    WHERE NOT cancelled AND NOT diverted
+    GROUP BY date, carrier, origin, destination, delay_bucket;
+
+-- Airport traffic, which ranks airport search results.
+UPDATE airports SET total_flights=
+    IFNULL((SELECT SUM(total_flights) FROM flights_day WHERE origin=airports.code), 0) +
+    IFNULL((SELECT SUM(total_flights) FROM flights_day WHERE destination=airports.code), 0);